@@ -1,16 +1,39 @@
 package main
 
 import (
+	"context"
 	"crypto_tracker/config"
+	"crypto_tracker/internal/alerts"
+	"crypto_tracker/internal/backfill"
+	"crypto_tracker/internal/fx"
+	"crypto_tracker/internal/gaps"
 	"crypto_tracker/internal/handlers/add"
+	alertsHandlers "crypto_tracker/internal/handlers/alerts"
+	analyticsHandlers "crypto_tracker/internal/handlers/analytics"
+	"crypto_tracker/internal/handlers/baskets"
+	"crypto_tracker/internal/handlers/coins"
+	"crypto_tracker/internal/handlers/collectors"
+	"crypto_tracker/internal/handlers/convert"
 	"crypto_tracker/internal/handlers/get"
+	"crypto_tracker/internal/handlers/jobs"
+	"crypto_tracker/internal/handlers/market"
+	"crypto_tracker/internal/handlers/pairs"
+	"crypto_tracker/internal/handlers/portfolios"
 	"crypto_tracker/internal/handlers/remove"
+	"crypto_tracker/internal/handlers/stream"
+	"crypto_tracker/internal/handlers/ws"
+	"crypto_tracker/internal/hub"
+	"crypto_tracker/internal/index"
+	"crypto_tracker/internal/ledger"
+	"crypto_tracker/internal/rates"
 	"crypto_tracker/internal/storage/pg"
+	"crypto_tracker/internal/tracker"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	_ "crypto_tracker/docs"
 
@@ -47,6 +70,30 @@ func main() {
 	log.Info("migration run is completed")
 	defer storage.Close()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fxCollector := fx.NewCollector(log, fx.NewFrankfurter(config.FXAPIUrl), storage, config.FXCurrencies,
+		config.FXInterval, config.FXHistory)
+	go fxCollector.Run(ctx)
+
+	if config.WebhookURL == "" {
+		log.Warn("webhook url is not set, alerts will only be logged")
+	}
+	events := hub.New(64)
+	evaluator := alerts.New(log, storage, alerts.NewWebhook(config.WebhookURL, config.WebhookSecret), events)
+	validator := tracker.NewValidator(log, storage, config.SpikePercent, config.SpikeWindow, config.ClockSkew)
+	restart := tracker.RestartPolicy{MaxCrashes: config.CollectorMaxCrashes, Backoff: config.CollectorBackoff}
+	collector := tracker.NewCollector(log, config.ExtAPIUrl, config.APIKey, storage, validator, restart, evaluator,
+		events)
+
+	gapThreshold := time.Duration(config.GapFactor*tracker.N) * time.Second
+	gapFiller := gaps.NewFiller(log, storage, collector, gapThreshold, config.GapLookback)
+	go gapFiller.Run(ctx, config.GapScanInterval)
+
+	backfiller := backfill.New(log, storage, collector, config.BackfillChunk, config.BackfillRate)
+	go backfiller.Run(ctx)
+
 	router := chi.NewRouter()
 	router.Use(middleware.Recoverer) // воостановление после паники (чтобы не падало приложение после 1 ошибки в хендлере)
 	router.Use(middleware.URLFormat)
@@ -55,9 +102,63 @@ func main() {
 	router.Get("/swagger/*", httpSwagger.WrapHandler)
 
 	// Настройка роутинга
-	router.Post("/currency/add", add.New(log, config.ExtAPIUrl, config.APIKey, storage))
+	router.Post("/currency/add", add.New(log, collector, backfiller))
 	router.Post("/currency/remove", remove.New(log))
 	router.Get("/currency/price", get.New(log, storage))
+	router.Get("/jobs/{id}", jobs.NewGet(log, storage))
+
+	router.Route("/alerts", func(r chi.Router) {
+		r.Post("/", alertsHandlers.NewCreate(log, storage))
+		r.Get("/", alertsHandlers.NewList(log, storage))
+		r.Get("/{id}", alertsHandlers.NewGet(log, storage))
+		r.Put("/{id}", alertsHandlers.NewUpdate(log, storage))
+		r.Delete("/{id}", alertsHandlers.NewDelete(log, storage))
+		r.Get("/{id}/events", alertsHandlers.NewEvents(log, storage))
+	})
+
+	router.Get("/stream/prices", stream.New(log, events, storage))
+	router.Get("/ws", ws.New(log, events))
+
+	crossRates := rates.New(storage, collector, config.FXCurrencies)
+	transactions := ledger.New(storage)
+	indexes := index.New(storage)
+	router.Route("/v1", func(r chi.Router) {
+		r.Get("/pairs/{base}/{quote}/price", pairs.NewPrice(log, crossRates))
+		r.Get("/pairs/{base}/{quote}/history", pairs.NewHistory(log, crossRates))
+		r.Get("/convert", convert.New(log, crossRates))
+
+		r.Route("/portfolios", func(r chi.Router) {
+			r.Post("/", portfolios.NewCreate(log, storage))
+			r.Get("/", portfolios.NewList(log, storage))
+			r.Get("/{id}", portfolios.NewGet(log, storage))
+			r.Delete("/{id}", portfolios.NewDelete(log, storage))
+			r.Put("/{id}/holdings", portfolios.NewSetHolding(log, storage, collector))
+			r.Delete("/{id}/holdings/{coin}", portfolios.NewDeleteHolding(log, storage))
+			r.Get("/{id}/value", portfolios.NewValue(log, storage))
+			r.Get("/{id}/value/history", portfolios.NewValueHistory(log, storage))
+			r.Post("/{id}/transactions", portfolios.NewCreateTransaction(log, storage, transactions))
+			r.Get("/{id}/transactions", portfolios.NewListTransactions(log, storage, transactions))
+			r.Delete("/{id}/transactions/{tx}", portfolios.NewDeleteTransaction(log, transactions))
+			r.Get("/{id}/pnl", portfolios.NewPnL(log, storage, transactions))
+		})
+		r.Get("/coins/{coin}/stats", coins.NewStats(log, storage))
+		r.Get("/coins/{coin}/indicators", coins.NewIndicators(log, storage))
+		r.Get("/coins/{coin}/history", coins.NewHistory(log, storage))
+		r.Get("/coins/{coin}/gaps", coins.NewGaps(log, storage, gapFiller))
+		r.Get("/coins/{coin}/quality", coins.NewQuality(log, storage, time.Duration(tracker.N)*time.Second))
+		r.Get("/analytics/correlation", analyticsHandlers.NewCorrelation(log, storage))
+		r.Get("/analytics/compare", analyticsHandlers.NewCompare(log, storage))
+		r.Get("/collectors", collectors.NewList(log, collector))
+		r.Get("/snapshot", market.NewSnapshot(log, storage, collector))
+		r.Get("/movers", market.NewMovers(log, storage, collector))
+		r.Route("/baskets", func(r chi.Router) {
+			r.Post("/", baskets.NewCreate(log, storage, collector))
+			r.Get("/", baskets.NewList(log, storage))
+			r.Get("/{id}", baskets.NewGet(log, storage))
+			r.Delete("/{id}", baskets.NewDelete(log, storage))
+			r.Get("/{id}/history", baskets.NewHistory(log, storage, indexes))
+		})
+	})
 
 	log.Info("starting server", slog.String("address", config.Address))
 
@@ -3,6 +3,8 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -14,6 +16,12 @@ type Config struct {
 	MigrationsPath string
 	HTTPServer
 	APIUrls
+	Webhook
+	FX
+	Gaps
+	Backfill
+	Validation
+	Supervisor
 }
 
 type HTTPServer struct {
@@ -27,6 +35,46 @@ type APIUrls struct {
 	APIKey    string
 }
 
+// FX - сбор курсов фиатных валют (необязательный, есть значения по умолчанию)
+type FX struct {
+	FXAPIUrl     string
+	FXCurrencies []string
+	FXInterval   time.Duration
+	FXHistory    time.Duration // За какой период курсы загружаются при запуске
+}
+
+// Gaps - фоновый поиск и дозаполнение пропусков в ценах (необязательный, есть значения по умолчанию)
+type Gaps struct {
+	GapFactor       int           // Пропуском считается промежуток длиннее GapFactor интервалов сбора
+	GapLookback     time.Duration // Насколько далеко в прошлое проверяются цены
+	GapScanInterval time.Duration // Как часто запускается проверка
+}
+
+// Backfill - загрузка истории цен по запросу (необязательный, есть значения по умолчанию)
+type Backfill struct {
+	BackfillChunk time.Duration // Длина периода, запрашиваемого у провайдера за один раз
+	BackfillRate  time.Duration // Минимальный интервал между запросами к провайдеру
+}
+
+// Validation - проверка цен перед сохранением (необязательный, есть значения по умолчанию)
+type Validation struct {
+	SpikePercent float64       // Допустимое отклонение цены от скользящей медианы в процентах
+	SpikeWindow  time.Duration // Окно скользящей медианы
+	ClockSkew    time.Duration // Насколько время цены может опережать текущее
+}
+
+// Supervisor - перезапуск сбора цен после паники (необязательный, есть значения по умолчанию)
+type Supervisor struct {
+	CollectorMaxCrashes int           // После стольких паник подряд сбор пары останавливается (состояние failed)
+	CollectorBackoff    time.Duration // Пауза перед первым перезапуском, удваивается после каждой паники
+}
+
+// Webhook для оповещений (необязательный: без URL оповещения только пишутся в лог)
+type Webhook struct {
+	WebhookURL    string
+	WebhookSecret string
+}
+
 func MustLoad() Config {
 
 	// Загружаем переменные окружения из файла .env
@@ -49,12 +97,51 @@ func MustLoad() Config {
 			ExtAPIUrl: checkAndReturnData("API_URL"),
 			APIKey:    checkAndReturnData("API_KEY"),
 		},
+		Webhook: Webhook{
+			WebhookURL:    os.Getenv("WEBHOOK_URL"),
+			WebhookSecret: os.Getenv("WEBHOOK_SECRET"),
+		},
+		FX: FX{
+			FXAPIUrl:     getEnvDefault("FX_API_URL", "https://api.frankfurter.app"),
+			FXCurrencies: parseCodes(getEnvDefault("FX_CURRENCIES", "EUR,GBP")),
+			FXInterval:   parseDuration(getEnvDefault("FX_INTERVAL", "1h")),
+			FXHistory:    parseDuration(getEnvDefault("FX_HISTORY", "8760h")),
+		},
+		Gaps: Gaps{
+			GapFactor:       parseInt(getEnvDefault("GAP_FACTOR", "3")),
+			GapLookback:     parseDuration(getEnvDefault("GAP_LOOKBACK", "24h")),
+			GapScanInterval: parseDuration(getEnvDefault("GAP_SCAN_INTERVAL", "1h")),
+		},
+		Backfill: Backfill{
+			BackfillChunk: parseDuration(getEnvDefault("BACKFILL_CHUNK", "24h")),
+			BackfillRate:  parseDuration(getEnvDefault("BACKFILL_RATE", "1s")),
+		},
+		Validation: Validation{
+			SpikePercent: parseFloat(getEnvDefault("SPIKE_PERCENT", "50")),
+			SpikeWindow:  parseDuration(getEnvDefault("SPIKE_WINDOW", "1h")),
+			ClockSkew:    parseDuration(getEnvDefault("CLOCK_SKEW", "1m")),
+		},
+		Supervisor: Supervisor{
+			CollectorMaxCrashes: parseInt(getEnvDefault("COLLECTOR_MAX_CRASHES", "5")),
+			CollectorBackoff:    parseDuration(getEnvDefault("COLLECTOR_BACKOFF", "1s")),
+		},
 	}
 
-	log.Printf("Config: %+v\n", config)
+	log.Printf("Config: %+v\n", config.redacted())
 	return config
 }
 
+// Копия конфигурации для логов: секреты заменены, чтобы не попадать в логи
+func (c Config) redacted() Config {
+	if c.WebhookSecret != "" {
+		c.WebhookSecret = "<redacted>"
+	}
+	if c.APIKey != "" {
+		c.APIKey = "<redacted>"
+	}
+	return c
+}
+
 // Проверка, что поля не пустые
 func checkAndReturnData(s string) string {
 	data := os.Getenv(s)
@@ -64,6 +151,14 @@ func checkAndReturnData(s string) string {
 	return data
 }
 
+// Значение переменной окружения или значение по умолчанию, если она не задана
+func getEnvDefault(s, def string) string {
+	if data := os.Getenv(s); data != "" {
+		return data
+	}
+	return def
+}
+
 // Преобразование строки во временной интервал
 func parseDuration(s string) time.Duration {
 	d, err := time.ParseDuration(s)
@@ -72,3 +167,32 @@ func parseDuration(s string) time.Duration {
 	}
 	return d
 }
+
+// Преобразование списка через запятую в коды валют в верхнем регистре без пустых элементов
+func parseCodes(s string) []string {
+	codes := make([]string, 0)
+	for _, code := range strings.Split(s, ",") {
+		if code = strings.ToUpper(strings.TrimSpace(code)); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// Преобразование строки в положительное целое число
+func parseInt(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		log.Fatalf("Error parsing positive integer %q: %v", s, err)
+	}
+	return n
+}
+
+// Преобразование строки в положительное число
+func parseFloat(s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f <= 0 {
+		log.Fatalf("Error parsing positive number %q: %v", s, err)
+	}
+	return f
+}
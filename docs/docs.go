@@ -23,31 +23,65 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/currency/add": {
+        "/alerts": {
+            "get": {
+                "description": "Возвращает все правила оповещения.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Список правил оповещения",
+                "operationId": "list-alerts",
+                "responses": {
+                    "200": {
+                        "description": "Правила оповещения",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Alert"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get alerts",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Добавляет криптовалюту в список отслеживаемых и начинает сбор данных о её цене.",
+                "description": "Создаёт правило: цена выше/ниже порога и (опционально) изменение цены в процентах за окно (window, например 1h).\nhysteresis_percent - на сколько процентов от порога цена должна вернуться, чтобы правило перешло в resolved.\nindicator (sma, ema, rsi, macd) и indicator_value - значение индикатора выше/ниже порога, например RSI выше 70.\nИндикатор считается по свечам длиной indicator_interval (по умолчанию 1h) с периодом indicator_period (по умолчанию 20, для rsi - 14; для macd - 12/26/9).\ncooldown - минимальная пауза между оповещениями (например 15m).",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Добавить криптовалюту для отслеживания",
-                "operationId": "add-coin",
+                "summary": "Создать правило оповещения",
+                "operationId": "create-alert",
                 "parameters": [
                     {
-                        "description": "Данные для добавления криптовалюты",
+                        "description": "Правило оповещения",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CoinRequest"
+                            "$ref": "#/definitions/models.AlertRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "message: Currency added to watchlist",
+                    "201": {
+                        "description": "Созданное правило",
+                        "schema": {
+                            "$ref": "#/definitions/models.Alert"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Validation failed",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -55,8 +89,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "400": {
-                        "description": "error: Coin is already being tracked",
+                    "500": {
+                        "description": "error: Failed to create alert",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -67,37 +101,105 @@ const docTemplate = `{
                 }
             }
         },
-        "/currency/price": {
+        "/alerts/{id}": {
             "get": {
-                "description": "Возвращает цену криптовалюты на указанный timestamp (timestamp в миллисекундах).",
+                "description": "Возвращает правило оповещения по идентификатору.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получить правило оповещения",
+                "operationId": "get-alert",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор правила",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Правило оповещения",
+                        "schema": {
+                            "$ref": "#/definitions/models.Alert"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid alert id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Alert not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get alert",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Полностью заменяет условия правила и сбрасывает его состояние в ok и паузу между оповещениями (last_notified_at).\nЕсли правило было в firing или resolved, сброс записывается в историю переходов с price = 0.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Получить цену криптовалюты",
-                "operationId": "get-coin",
+                "summary": "Изменить правило оповещения",
+                "operationId": "update-alert",
                 "parameters": [
                     {
-                        "description": "Данные для получения цены",
+                        "type": "integer",
+                        "description": "Идентификатор правила",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Правило оповещения",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.GetPriceRequest"
+                            "$ref": "#/definitions/models.AlertRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Цена криптовалюты",
+                        "description": "Изменённое правило",
                         "schema": {
-                            "$ref": "#/definitions/models.Coin"
+                            "$ref": "#/definitions/models.Alert"
                         }
                     },
                     "400": {
-                        "description": "error: Failed to get price",
+                        "description": "error: Validation failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Alert not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -106,7 +208,62 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "error: Failed to get price",
+                        "description": "error: Failed to update alert",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет правило оповещения по идентификатору.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удалить правило оповещения",
+                "operationId": "delete-alert",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор правила",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message: Alert deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid alert id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Alert not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to delete alert",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -117,20 +274,77 @@ const docTemplate = `{
                 }
             }
         },
-        "/currency/remove": {
+        "/alerts/{id}/events": {
+            "get": {
+                "description": "Возвращает переходы правила между состояниями (ok, firing, resolved) в хронологическом порядке.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "История состояний правила оповещения",
+                "operationId": "get-alert-events",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор правила",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Переходы состояний",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AlertEvent"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid alert id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Alert not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get alert events",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/add": {
             "post": {
-                "description": "Удаляет криптовалюту из списка отслеживаемых и останавливает сбор данных о её цене.",
+                "description": "Добавляет криптовалюту в список отслеживаемых и начинает сбор данных о её цене в валюте котировки quote (по умолчанию USD).\nЕсли передан backfill_from, создаётся задача загрузки истории цен с этого времени; её состояние доступно по GET /jobs/{id}.\nЕсли задачу создать не удалось, валюта всё равно добавляется, а в ответе возвращается backfill_error.\nЕсли провайдер недоступен и allow_pending = true, валюта добавляется в состоянии pending_validation (ответ 202):\nпроверка повторяется в фоне, после неё начинается сбор цен.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Удалить криптовалюту из отслеживаемых",
-                "operationId": "remove-coin",
+                "summary": "Добавить криптовалюту для отслеживания",
+                "operationId": "add-coin",
                 "parameters": [
                     {
-                        "description": "Данные для удаления криптовалюты",
+                        "description": "Данные для добавления криптовалюты",
                         "name": "request",
                         "in": "body",
                         "required": true,
@@ -141,16 +355,87 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "message: Currency removed from watchlist",
+                        "description": "message: Currency added to watchlist",
+                        "schema": {
+                            "$ref": "#/definitions/models.AddCoinResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "message: Currency pending validation",
+                        "schema": {
+                            "$ref": "#/definitions/models.AddCoinResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Coin is already being tracked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to add currency",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "error: Provider unavailable, retry_after: секунды",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "error: Provider rate limit exceeded, retry_after: секунды",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
+                    }
+                }
+            }
+        },
+        "/currency/price": {
+            "get": {
+                "description": "Возвращает цену криптовалюты на указанный timestamp (timestamp в миллисекундах) в валюте котировки quote (по умолчанию USD).\nЕсли указан convert (например EUR), цена пересчитывается по курсу фиатной валюты, действовавшему в момент цены.\nЕсли provenance = true, возвращаются источник цены, исходное время провайдера, время получения и задержка (без convert).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получить цену криптовалюты",
+                "operationId": "get-coin",
+                "parameters": [
+                    {
+                        "description": "Данные для получения цены",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GetPriceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Цена криптовалюты",
+                        "schema": {
+                            "$ref": "#/definitions/models.Coin"
+                        }
                     },
                     "400": {
-                        "description": "error: Coin field is required",
+                        "description": "error: Failed to get price",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -159,7 +444,16 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "error: Coin is not tracked",
+                        "description": "error: FX rate not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get price",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -169,9 +463,2464 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
+        },
+        "/currency/remove": {
+            "post": {
+                "description": "Удаляет пару криптовалюта/котировка (по умолчанию USD) из списка отслеживаемых и останавливает сбор данных о её цене.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удалить криптовалюту из отслеживаемых",
+                "operationId": "remove-coin",
+                "parameters": [
+                    {
+                        "description": "Данные для удаления криптовалюты",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CoinRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message: Currency removed from watchlist",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Coin field is required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Coin is not tracked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "description": "Возвращает статус (queued, running, done, failed) и прогресс задачи загрузки истории цен.\nНезавершённые задачи продолжаются с сохранённой позиции после перезапуска сервиса.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получить задачу загрузки истории",
+                "operationId": "get-job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Задача",
+                        "schema": {
+                            "$ref": "#/definitions/models.BackfillJob"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid job id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Job not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get job",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/stream/prices": {
+            "get": {
+                "description": "Отправляет событие price при каждом сохранении новой цены. Раз в 15 секунд отправляется комментарий-heartbeat.\nПри переподключении заголовок Last-Event-ID позволяет получить из базы цены, сохранённые после этого события.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "summary": "Поток цен (Server-Sent Events)",
+                "operationId": "stream-prices",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Список валют через запятую (по умолчанию все)",
+                        "name": "coins",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта котировки (по умолчанию USD)",
+                        "name": "quote",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор последнего полученного события",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Поток событий price",
+                        "schema": {
+                            "$ref": "#/definitions/models.Coin"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid Last-Event-ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Streaming unsupported",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/analytics/compare": {
+            "get": {
+                "description": "Возвращает цены валют в моменты since, since+step, ..., to, приведённые к 100 в момент since.\nВсе валюты приводятся к сетке по одним правилам: цена в момент t агрегируется (agg) из цен интервала\n(t - step, t], пустые интервалы заполняются (fill, по умолчанию previous - forward-fill).\nЕсли у валюты нет значения на since, за 100 принимается её первое значение на сетке, а значения до него равны null.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Сравнение динамики валют",
+                "operationId": "get-comparison",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Валюты через запятую (до 20)",
+                        "name": "coins",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Начальный момент, timestamp в миллисекундах (по умолчанию to - 24h)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Конечный момент, timestamp в миллисекундах (по умолчанию текущее время)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Шаг сетки (по умолчанию 1h)",
+                        "name": "step",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Заполнение пустых интервалов: none, previous, linear, zero (по умолчанию previous)",
+                        "name": "fill",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Агрегация цен в интервале: last, mean, first (по умолчанию last)",
+                        "name": "agg",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Котировка цен (по умолчанию USD)",
+                        "name": "quote",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сравнение",
+                        "schema": {
+                            "$ref": "#/definitions/models.Comparison"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid query parameters",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get comparison",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/analytics/correlation": {
+            "get": {
+                "description": "Группирует цены валют за окно [to - window, to) в свечи длиной interval и считает логарифмические доходности\nмежду соседними свечами. Для каждой пары валют корреляция считается только по общим интервалам;\nих число возвращается в samples. Бета считается относительно benchmark (по умолчанию первая валюта).",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Матрица корреляций и беты",
+                "operationId": "get-correlation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Валюты через запятую (от 2 до 20)",
+                        "name": "coins",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Окно (по умолчанию 30d)",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Шаг сетки (по умолчанию 1h)",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Конец окна, timestamp в миллисекундах (по умолчанию текущее время)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта для расчёта беты (по умолчанию первая из coins)",
+                        "name": "benchmark",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Котировка цен (по умолчанию USD)",
+                        "name": "quote",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Матрица корреляций",
+                        "schema": {
+                            "$ref": "#/definitions/models.CorrelationMatrix"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid query parameters",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get correlation",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/baskets": {
+            "get": {
+                "description": "Возвращает все корзины с составом.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Список корзин",
+                "operationId": "list-baskets",
+                "responses": {
+                    "200": {
+                        "description": "Корзины",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Basket"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get baskets",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создаёт индекс из взвешенного набора валют. Веса нормируются так, чтобы их сумма была 1.\nВалюты корзины, которые ещё не отслеживаются, добавляются в список отслеживаемых в котировке корзины.\nЕсли провайдер недоступен, корзина не создаётся: возвращается 502 или 503 с видом ошибки kind.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Создать корзину",
+                "operationId": "create-basket",
+                "parameters": [
+                    {
+                        "description": "Корзина",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BasketRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданная корзина",
+                        "schema": {
+                            "$ref": "#/definitions/models.Basket"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid coin",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Provider misconfigured",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "error: Provider unavailable, coin: валюта, kind: вид ошибки провайдера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "error: Provider rate limit exceeded, coin: валюта, kind: rate_limited",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/baskets/{id}": {
+            "get": {
+                "description": "Возвращает корзину с составом.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получить корзину",
+                "operationId": "get-basket",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор корзины",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Корзина",
+                        "schema": {
+                            "$ref": "#/definitions/models.Basket"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid basket id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Basket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get basket",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет корзину. Сбор цен её валют не останавливается.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удалить корзину",
+                "operationId": "delete-basket",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор корзины",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message: Basket deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid basket id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Basket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to delete basket",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/baskets/{id}/history": {
+            "get": {
+                "description": "Возвращает уровень индекса в моменты from, from+step, ..., to (не более 1000 точек, не раньше base_time).\nВ base_time индекс равен 100; цены берутся последние не позже каждого момента.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "История индекса корзины",
+                "operationId": "get-basket-history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор корзины",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Начало периода, timestamp в миллисекундах (по умолчанию to - 24h)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Конец периода, timestamp в миллисекундах (по умолчанию текущее время)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Шаг (по умолчанию 1h)",
+                        "name": "step",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Уровень индекса по времени",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.IndexPoint"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid query parameters",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Price not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get basket history",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coins/{coin}/gaps": {
+            "get": {
+                "description": "Возвращает промежутки между соседними сохранёнными ценами за окно, длиннее порога\n(по умолчанию - порог фоновой задачи дозаполнения), и результат последней проверки этой задачей.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Пропуски в ценах",
+                "operationId": "get-coin-gaps",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Валюта (например Bitcoin)",
+                        "name": "coin",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Окно (по умолчанию 24h, не больше 90d)",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Минимальная длина пропуска (по умолчанию GAP_FACTOR интервалов сбора)",
+                        "name": "threshold",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Котировка цен (по умолчанию USD)",
+                        "name": "quote",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пропуски",
+                        "schema": {
+                            "$ref": "#/definitions/models.GapReport"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid query parameters",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get gaps",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coins/{coin}/history": {
+            "get": {
+                "description": "Возвращает цену валюты в моменты from, from+step, ..., to (не более 1000 точек).\nЦена в момент t агрегируется (agg) из цен интервала (t - step, t], пустые интервалы заполняются (fill);\nзаполненные точки помечаются filled.\nЕсли указан convert (например EUR), значение каждой точки пересчитывается по курсу фиатной валюты, действовавшему в её момент.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "История цены на регулярной сетке",
+                "operationId": "get-coin-history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Валюта (например Bitcoin)",
+                        "name": "coin",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Начало периода, timestamp в миллисекундах (по умолчанию to - 24h)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Конец периода, timestamp в миллисекундах (по умолчанию текущее время)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Шаг сетки (по умолчанию 1h)",
+                        "name": "step",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Заполнение пустых интервалов: none, previous, linear, zero (по умолчанию previous)",
+                        "name": "fill",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Агрегация цен в интервале: last, mean, first (по умолчанию last)",
+                        "name": "agg",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Котировка цен (по умолчанию USD)",
+                        "name": "quote",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фиатная валюта для пересчёта (например EUR)",
+                        "name": "convert",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "История цены",
+                        "schema": {
+                            "$ref": "#/definitions/models.PriceHistory"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid query parameters",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: FX rate not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get history",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coins/{coin}/indicators": {
+            "get": {
+                "description": "Вычисляет индикатор по ценам закрытия свечей длиной interval, собранных из сохранённых цен за [from, to).\nДля разгона индикатора дополнительно загружаются свечи до from; точки, где индикатор ещё не определён, не возвращаются.\nbollinger возвращает middle, upper, lower; macd - macd, signal, histogram.\nЕсли указан convert (например EUR), свечи пересчитываются по курсу фиатной валюты, действовавшему в начале каждой свечи.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Технический индикатор",
+                "operationId": "get-coin-indicators",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Валюта (например Bitcoin)",
+                        "name": "coin",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Индикатор: sma, ema, rsi, bollinger, macd",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Период (по умолчанию 20, для rsi - 14)",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Быстрый период macd (по умолчанию 12)",
+                        "name": "fast",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Медленный период macd (по умолчанию 26)",
+                        "name": "slow",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Период сигнальной линии macd (по умолчанию 9)",
+                        "name": "signal",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Ширина полос bollinger в стандартных отклонениях (по умолчанию 2)",
+                        "name": "k",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Длина свечи (по умолчанию 1h)",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Начало периода, timestamp в миллисекундах (по умолчанию to - 24h)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Конец периода, timestamp в миллисекундах (по умолчанию текущее время)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Котировка цен (по умолчанию USD)",
+                        "name": "quote",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фиатная валюта для пересчёта (например EUR)",
+                        "name": "convert",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Значения индикатора",
+                        "schema": {
+                            "$ref": "#/definitions/models.IndicatorSeries"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid query parameters",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: FX rate not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get indicator",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coins/{coin}/quality": {
+            "get": {
+                "description": "Сравнивает число сохранённых цен за окно [to - window, to] с ожидаемым при сборе раз в интервал коллектора,\nвозвращает покрытие (различные моменты времени от ожидаемого числа), самые длинные промежутки между ценами,\nчисло цен с повторяющимся временем и цен, отклонённых проверкой перед сохранением.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Качество ряда цен",
+                "operationId": "get-coin-quality",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Валюта (например Bitcoin)",
+                        "name": "coin",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Окно (по умолчанию 7d, не больше 90d)",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Конец окна, timestamp в миллисекундах (по умолчанию текущее время)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Число самых длинных пропусков (по умолчанию 5, не больше 100)",
+                        "name": "gaps",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Котировка цен (по умолчанию USD)",
+                        "name": "quote",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Отчёт о качестве",
+                        "schema": {
+                            "$ref": "#/definitions/models.DataQuality"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid query parameters",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get quality",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coins/{coin}/stats": {
+            "get": {
+                "description": "Считает по сохранённым ценам за окно (to - window, to] изменение, минимум, максимум, среднее,\nстандартное отклонение и годовую волатильность логарифмических доходностей.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Статистика цены за окно",
+                "operationId": "get-coin-stats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Валюта (например Bitcoin)",
+                        "name": "coin",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Окно: 24h, 7d, 30d и т.п. (по умолчанию 24h)",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Конец окна, timestamp в миллисекундах (по умолчанию текущее время)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Котировка цен (по умолчанию USD)",
+                        "name": "quote",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Статистика",
+                        "schema": {
+                            "$ref": "#/definitions/models.CoinStats"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid query parameters",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: No prices in window",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get stats",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/collectors": {
+            "get": {
+                "description": "Возвращает состояние горутин сбора цен отслеживаемых пар (pending_validation, running, restarting, failed),\nчисло паник, время последней сохранённой цены,\nпоследнюю ошибку, её вид (not_found, rate_limited, unauthorized, upstream, malformed, internal)\nи число ошибок подряд.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Состояние сбора цен",
+                "operationId": "list-collectors",
+                "responses": {
+                    "200": {
+                        "description": "Состояние сбора",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CollectorStatus"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/convert": {
+            "get": {
+                "description": "Пересчитывает amount из from в to по ценам и курсам, действовавшим в момент at.\nfrom и to - название криптовалюты (Bitcoin), её тикер (BTC) или код валюты (USD, EUR).\nТикер распознаётся, когда коллектор уже получил хотя бы одну цену этой валюты.\nФиатными считаются USD и валюты из FX_CURRENCIES: они пересчитываются по курсам fx_rates.\nВ legs возвращаются все использованные цены и курсы с точным временем выборки.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Конвертировать сумму на момент времени",
+                "operationId": "convert",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Сумма (положительное число)",
+                        "name": "amount",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Из чего конвертировать (например Bitcoin)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Во что конвертировать (например Ethereum или EUR)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Timestamp в миллисекундах (по умолчанию текущее время)",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Результат конвертации",
+                        "schema": {
+                            "$ref": "#/definitions/models.Conversion"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid query parameters",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Price or fx rate not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to convert",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/movers": {
+            "get": {
+                "description": "Возвращает limit отслеживаемых в котировке quote валют с наибольшим ростом и limit валют\nс наибольшим падением цены за window.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Лидеры роста и падения",
+                "operationId": "get-movers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Окно (по умолчанию 24h)",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Число валют в каждом списке (по умолчанию 10, не больше 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Котировка цен (по умолчанию USD)",
+                        "name": "quote",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Лидеры роста и падения",
+                        "schema": {
+                            "$ref": "#/definitions/models.Movers"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid query parameters",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get movers",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/pairs/{base}/{quote}/history": {
+            "get": {
+                "description": "Вычисляет курс base/quote для каждой цены base за период [from, to], выравнивая по времени цены quote в пределах tolerance.\nЦены base без пары пропускаются (поле skipped). Если выровненных цен нет, возвращается 404.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "История кросс-курса двух отслеживаемых валют",
+                "operationId": "get-pair-history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Базовая валюта (например Ethereum)",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Котируемая валюта (например Bitcoin)",
+                        "name": "quote",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Начало периода, timestamp в миллисекундах (по умолчанию to - 24h, период не больше 7d)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Конец периода, timestamp в миллисекундах (по умолчанию текущее время)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Допустимое расхождение времени цен (по умолчанию 1m)",
+                        "name": "tolerance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Общая котировка (по умолчанию USD)",
+                        "name": "via",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "История кросс-курса",
+                        "schema": {
+                            "$ref": "#/definitions/models.CrossRateHistory"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid query parameters",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: No price within tolerance",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get cross rate history",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/pairs/{base}/{quote}/price": {
+            "get": {
+                "description": "Вычисляет курс base/quote (например Ethereum/Bitcoin) по ценам обеих валют в котировке via (по умолчанию USD).\nДля каждой валюты берётся цена, ближайшая к at, но не дальше tolerance; иначе возвращается 404.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Кросс-курс двух отслеживаемых валют",
+                "operationId": "get-pair-price",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Базовая валюта (например Ethereum)",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Котируемая валюта (например Bitcoin)",
+                        "name": "quote",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Timestamp в миллисекундах (по умолчанию текущее время)",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Допустимое расхождение времени цен (по умолчанию 1m)",
+                        "name": "tolerance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Общая котировка (по умолчанию USD)",
+                        "name": "via",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Кросс-курс",
+                        "schema": {
+                            "$ref": "#/definitions/models.CrossRate"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid query parameters",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: No price within tolerance",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get cross rate",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/portfolios": {
+            "get": {
+                "description": "Возвращает все портфели с позициями.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Список портфелей",
+                "operationId": "list-portfolios",
+                "responses": {
+                    "200": {
+                        "description": "Портфели",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Portfolio"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get portfolios",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создаёт пустой портфель.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Создать портфель",
+                "operationId": "create-portfolio",
+                "parameters": [
+                    {
+                        "description": "Портфель",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PortfolioRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданный портфель",
+                        "schema": {
+                            "$ref": "#/definitions/models.Portfolio"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid request body",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to create portfolio",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/portfolios/{id}": {
+            "get": {
+                "description": "Возвращает портфель с позициями.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получить портфель",
+                "operationId": "get-portfolio",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Портфель",
+                        "schema": {
+                            "$ref": "#/definitions/models.Portfolio"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid portfolio id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Portfolio not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get portfolio",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет портфель вместе с позициями.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удалить портфель",
+                "operationId": "delete-portfolio",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message: Portfolio deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid portfolio id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Portfolio not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to delete portfolio",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/portfolios/{id}/holdings": {
+            "put": {
+                "description": "Задаёт количество валюты в портфеле. Если track = true и валюта не отслеживается,\nона добавляется в список отслеживаемых так же, как через /currency/add (котировка USD).\nЕсли провайдер недоступен, позиция не сохраняется: возвращается 502 или 503 с видом ошибки kind.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Добавить или изменить позицию",
+                "operationId": "set-holding",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Позиция",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.HoldingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Портфель",
+                        "schema": {
+                            "$ref": "#/definitions/models.Portfolio"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid coin",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Portfolio not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Provider misconfigured",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "error: Provider unavailable, kind: вид ошибки провайдера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "error: Provider rate limit exceeded, kind: rate_limited",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/portfolios/{id}/holdings/{coin}": {
+            "delete": {
+                "description": "Удаляет валюту из портфеля. Сбор цен валюты не останавливается.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удалить позицию",
+                "operationId": "delete-holding",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта",
+                        "name": "coin",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message: Holding deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid portfolio id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Holding not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to delete holding",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/portfolios/{id}/pnl": {
+            "get": {
+                "description": "Восстанавливает позиции по транзакциям не позже at и считает себестоимость методом fifo, lifo или average.\nРеализованная прибыль - по продажам, нереализованная - по открытым позициям,\nоценённым по последней цене из истории не позже at и не раньше at - tolerance. Суммы - в USD.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Прибыль и убыток портфеля",
+                "operationId": "get-portfolio-pnl",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Timestamp в миллисекундах (по умолчанию текущее время)",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Метод себестоимости: fifo, lifo, average (по умолчанию fifo)",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Допустимое расстояние до цены из истории (по умолчанию 5m)",
+                        "name": "tolerance",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Прибыль и убыток",
+                        "schema": {
+                            "$ref": "#/definitions/models.PortfolioPnL"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid query parameters",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: No price within tolerance",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get PnL",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/portfolios/{id}/transactions": {
+            "get": {
+                "description": "Возвращает транзакции портфеля в хронологическом порядке.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Список транзакций",
+                "operationId": "list-transactions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Транзакции",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Transaction"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid portfolio id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Portfolio not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get transactions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Записывает покупку, продажу, поступление или вывод валюты. Цена и комиссия - в USD.\nЕсли цена не указана, берётся ближайшая к timestamp цена из истории не дальше tolerance.\nТранзакция, после которой количество валюты станет отрицательным, отклоняется.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Добавить транзакцию",
+                "operationId": "create-transaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Допустимое расстояние до цены из истории (по умолчанию 5m)",
+                        "name": "tolerance",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Транзакция",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TransactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Записанная транзакция",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Insufficient quantity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: No price within tolerance",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to create transaction",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/portfolios/{id}/transactions/{tx}": {
+            "delete": {
+                "description": "Удаляет транзакцию, если без неё количество валюты нигде в журнале не становится отрицательным.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удалить транзакцию",
+                "operationId": "delete-transaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор транзакции",
+                        "name": "tx",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message: Transaction deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Insufficient quantity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Transaction not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to delete transaction",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/portfolios/{id}/value": {
+            "get": {
+                "description": "Оценивает портфель в момент at по последним ценам позиций не позже at.\nПозиции без цены перечисляются в missing и не входят в total.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Стоимость портфеля",
+                "operationId": "get-portfolio-value",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Timestamp в миллисекундах (по умолчанию текущее время)",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Котировка цен (по умолчанию USD)",
+                        "name": "quote",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Стоимость портфеля",
+                        "schema": {
+                            "$ref": "#/definitions/models.PortfolioValue"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid query parameters",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Portfolio not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get portfolio value",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/portfolios/{id}/value/history": {
+            "get": {
+                "description": "Оценивает портфель в моменты from, from+step, ..., to (не более 1000 точек).\nЦена позиции в момент t агрегируется (agg) из цен интервала (t - step, t], пустые интервалы заполняются (fill).",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "История стоимости портфеля",
+                "operationId": "get-portfolio-value-history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Начало периода, timestamp в миллисекундах (по умолчанию to - 24h)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Конец периода, timestamp в миллисекундах (по умолчанию текущее время)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Шаг (по умолчанию 1h)",
+                        "name": "step",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Заполнение пустых интервалов: none, previous, linear, zero (по умолчанию previous)",
+                        "name": "fill",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Агрегация цен в интервале: last, mean, first (по умолчанию last)",
+                        "name": "agg",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Котировка цен (по умолчанию USD)",
+                        "name": "quote",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Стоимость портфеля по времени",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PortfolioValue"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid query parameters",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: Portfolio not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get portfolio value",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/snapshot": {
+            "get": {
+                "description": "Возвращает последнюю сохранённую цену каждой отслеживаемой в котировке quote валюты и её изменение\nв процентах за 1h, 24h и 7d (относительно последней цены не позже времени последней цены минус окно).",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Снимок рынка",
+                "operationId": "get-snapshot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Валюты через запятую (по умолчанию все отслеживаемые)",
+                        "name": "coins",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Котировка цен (по умолчанию USD)",
+                        "name": "quote",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Сортировка: coin, price, change_1h, change_24h, change_7d (по умолчанию coin)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Порядок: asc, desc (по умолчанию asc)",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Снимок рынка",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SnapshotEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid query parameters",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: Failed to get snapshot",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/ws": {
+            "get": {
+                "description": "Клиент отправляет {\"action\":\"subscribe|unsubscribe\",\"channel\":\"prices|alerts\",\"coin\":\"Bitcoin\",\"quote\":\"USD\"}.\nПодписка относится к валюте в котировке quote (по умолчанию USD): цены и оповещения в других котировках не приходят.\nСервер отправляет кадры {\"type\":\"price|alert\",\"coin\":...,\"quote\":...,\"data\":...}, подтверждения subscribed/unsubscribed и error.\nКлиент, не успевающий читать кадры, отключается с кодом 1013.",
+                "summary": "Подписка на цены и оповещения (WebSocket)",
+                "operationId": "ws",
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    }
+                }
+            }
+        }
+    },
     "definitions": {
+        "models.AddCoinResponse": {
+            "type": "object",
+            "properties": {
+                "backfill_error": {
+                    "description": "Если задачу загрузки истории создать не удалось: валюта всё равно отслеживается, загрузку можно запросить повторно",
+                    "type": "string"
+                },
+                "job_id": {
+                    "description": "Задача загрузки истории, если передан backfill_from",
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "state": {
+                    "description": "running или pending_validation",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Alert": {
+            "type": "object",
+            "properties": {
+                "change_percent": {
+                    "type": "number"
+                },
+                "coin": {
+                    "type": "string"
+                },
+                "condition": {
+                    "type": "string"
+                },
+                "cooldown": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "hysteresis_percent": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "indicator": {
+                    "type": "string"
+                },
+                "indicator_interval": {
+                    "type": "string"
+                },
+                "indicator_period": {
+                    "type": "integer"
+                },
+                "indicator_value": {
+                    "type": "number"
+                },
+                "last_notified_at": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "window": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AlertEvent": {
+            "type": "object",
+            "properties": {
+                "alert_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "from_state": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "notified": {
+                    "type": "boolean"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "to_state": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AlertRequest": {
+            "type": "object",
+            "required": [
+                "coin",
+                "condition"
+            ],
+            "properties": {
+                "change_percent": {
+                    "type": "number"
+                },
+                "coin": {
+                    "type": "string"
+                },
+                "condition": {
+                    "type": "string",
+                    "enum": [
+                        "above",
+                        "below"
+                    ]
+                },
+                "cooldown": {
+                    "type": "string"
+                },
+                "hysteresis_percent": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "indicator": {
+                    "type": "string",
+                    "enum": [
+                        "sma",
+                        "ema",
+                        "rsi",
+                        "macd"
+                    ]
+                },
+                "indicator_interval": {
+                    "type": "string"
+                },
+                "indicator_period": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 0
+                },
+                "indicator_value": {
+                    "type": "number"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "window": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BackfillJob": {
+            "type": "object",
+            "properties": {
+                "coin": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "cursor": {
+                    "description": "История загружена до этого времени",
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "inserted": {
+                    "description": "Добавлено цен",
+                    "type": "integer"
+                },
+                "progress": {
+                    "description": "Доля загруженного периода в процентах",
+                    "type": "number"
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "queued, running, done, failed",
+                    "type": "string"
+                },
+                "to": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Basket": {
+            "type": "object",
+            "properties": {
+                "base_time": {
+                    "description": "Момент, в который уровень индекса равен 100",
+                    "type": "integer"
+                },
+                "components": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BasketComponent"
+                    }
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "rebalance": {
+                    "description": "none, daily, weekly, monthly",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BasketComponent": {
+            "type": "object",
+            "required": [
+                "coin"
+            ],
+            "properties": {
+                "coin": {
+                    "type": "string"
+                },
+                "weight": {
+                    "description": "Доля в индексе; веса нормируются так, чтобы их сумма была 1",
+                    "type": "number"
+                }
+            }
+        },
+        "models.BasketRequest": {
+            "type": "object",
+            "required": [
+                "base_time",
+                "components",
+                "name"
+            ],
+            "properties": {
+                "base_time": {
+                    "type": "integer"
+                },
+                "components": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/models.BasketComponent"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "rebalance": {
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "daily",
+                        "weekly",
+                        "monthly"
+                    ]
+                }
+            }
+        },
         "models.Coin": {
             "type": "object",
             "properties": {
@@ -181,31 +2930,979 @@ const docTemplate = `{
                 "price": {
                     "type": "number"
                 },
+                "provenance": {
+                    "description": "Возвращается только по запросу",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Provenance"
+                        }
+                    ]
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CoinRequest": {
+            "type": "object",
+            "properties": {
+                "allow_pending": {
+                    "description": "Добавить без проверки, если провайдер недоступен",
+                    "type": "boolean"
+                },
+                "backfill_from": {
+                    "description": "Загрузить историю цен начиная с этого времени (мс)",
+                    "type": "integer"
+                },
+                "coin": {
+                    "type": "string"
+                },
+                "quote": {
+                    "description": "USD, EUR, BTC, ... (по умолчанию USD)",
+                    "type": "string"
+                }
+            }
+        },
+        "models.CoinStats": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "description": "last - first",
+                    "type": "number"
+                },
+                "change_percent": {
+                    "description": "(last - first) / first * 100",
+                    "type": "number"
+                },
+                "coin": {
+                    "type": "string"
+                },
+                "first": {
+                    "type": "number"
+                },
+                "first_timestamp": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "last": {
+                    "type": "number"
+                },
+                "last_timestamp": {
+                    "type": "integer"
+                },
+                "max": {
+                    "type": "number"
+                },
+                "mean": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "returns_std_dev": {
+                    "description": "Стандартное отклонение логарифмических доходностей между соседними ценами",
+                    "type": "number"
+                },
+                "samples": {
+                    "type": "integer"
+                },
+                "std_dev": {
+                    "type": "number"
+                },
+                "to": {
+                    "type": "integer"
+                },
+                "volatility": {
+                    "description": "Годовая волатильность логарифмических доходностей",
+                    "type": "number"
+                },
+                "window": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CollectorStatus": {
+            "type": "object",
+            "properties": {
+                "coin": {
+                    "type": "string"
+                },
+                "consecutive_errors": {
+                    "description": "Ошибок подряд с последней сохранённой цены",
+                    "type": "integer"
+                },
+                "crashes": {
+                    "description": "Паник с момента добавления пары",
+                    "type": "integer"
+                },
+                "last_crash": {
+                    "type": "string"
+                },
+                "last_crash_at": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "description": "Последняя ошибка сбора",
+                    "type": "string"
+                },
+                "last_error_at": {
+                    "type": "integer"
+                },
+                "last_error_kind": {
+                    "description": "not_found, rate_limited, unauthorized, upstream, malformed, internal",
+                    "type": "string"
+                },
+                "last_success": {
+                    "description": "Время последней сохранённой цены",
+                    "type": "integer"
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "integer"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Comparison": {
+            "type": "object",
+            "properties": {
+                "quote": {
+                    "type": "string"
+                },
+                "series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ComparisonSeries"
+                    }
+                },
+                "since": {
+                    "type": "integer"
+                },
+                "step": {
+                    "type": "string"
+                },
+                "timestamps": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ComparisonSeries": {
+            "type": "object",
+            "properties": {
+                "base_price": {
+                    "description": "Цена, принятая за 100; null, если цен за период нет",
+                    "type": "number"
+                },
+                "base_timestamp": {
+                    "description": "Момент сетки, на который взята базовая цена",
+                    "type": "integer"
+                },
+                "change": {
+                    "description": "Изменение в процентах к последнему моменту сетки",
+                    "type": "number"
+                },
+                "coin": {
+                    "type": "string"
+                },
+                "values": {
+                    "description": "Значения в моменты timestamps; null, если значения нет",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "models.Conversion": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "at": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "legs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ConversionLeg"
+                    }
+                },
+                "rate": {
+                    "type": "number"
+                },
+                "result": {
+                    "type": "number"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ConversionLeg": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "type": "string"
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "number"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "price - цена из coins, fx - курс из fx_rates",
+                    "type": "string"
+                }
+            }
+        },
+        "models.CorrelationMatrix": {
+            "type": "object",
+            "properties": {
+                "benchmark": {
+                    "type": "string"
+                },
+                "beta": {
+                    "description": "Бета coins[i] относительно benchmark",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "beta_samples": {
+                    "description": "Число общих доходностей coins[i] и benchmark",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "coins": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "correlation": {
+                    "description": "Корреляция доходностей coins[i] и coins[j]",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number"
+                        }
+                    }
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "returns": {
+                    "description": "Число доходностей каждой валюты",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "samples": {
+                    "description": "Число общих доходностей coins[i] и coins[j]",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "to": {
+                    "type": "integer"
+                },
+                "window": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CrossRate": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "type": "string"
+                },
+                "base_price": {
+                    "type": "number"
+                },
+                "base_timestamp": {
+                    "type": "integer"
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "quote_price": {
+                    "type": "number"
+                },
+                "quote_timestamp": {
+                    "type": "integer"
+                },
+                "rate": {
+                    "type": "number"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "via": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CrossRateHistory": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CrossRate"
+                    }
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "skipped": {
+                    "description": "Цены базовой валюты без цены котируемой в пределах tolerance",
+                    "type": "integer"
+                },
+                "tolerance": {
+                    "type": "string"
+                },
+                "via": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DataQuality": {
+            "type": "object",
+            "properties": {
+                "actual": {
+                    "description": "Сохранено цен",
+                    "type": "integer"
+                },
+                "coin": {
+                    "type": "string"
+                },
+                "coverage": {
+                    "description": "Различные моменты времени от ожидаемого числа, в процентах",
+                    "type": "number"
+                },
+                "duplicates": {
+                    "description": "Цены на уже сохранённое время, отклонённые при сохранении",
+                    "type": "integer"
+                },
+                "expected": {
+                    "description": "Ожидаемое число цен при сборе без пропусков",
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "interval": {
+                    "description": "Интервал сбора цен",
+                    "type": "string"
+                },
+                "largest_gaps": {
+                    "description": "Самые длинные промежутки между ценами",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Gap"
+                    }
+                },
+                "median_ingest_latency": {
+                    "description": "Медианная задержка между временем цены и её получением коллектором, мс",
+                    "type": "integer"
+                },
+                "providers": {
+                    "description": "Источники цен",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "quarantined": {
+                    "description": "Цены, отклонённые проверкой перед сохранением",
+                    "type": "integer"
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "integer"
+                },
+                "window": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Gap": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "type": "integer"
+                },
+                "from": {
+                    "description": "Время цены перед пропуском",
+                    "type": "integer"
+                },
+                "to": {
+                    "description": "Время цены после пропуска",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GapReport": {
+            "type": "object",
+            "properties": {
+                "coin": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "gaps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Gap"
+                    }
+                },
+                "last_scan": {
+                    "description": "null, если фоновая задача ещё не проверяла валюту",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.GapScan"
+                        }
+                    ]
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "description": "Пропуски длиннее порога считаются пропусками",
+                    "type": "string"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GapScan": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "gaps": {
+                    "description": "Найдено пропусков",
+                    "type": "integer"
+                },
+                "recovered": {
+                    "description": "Восстановлено цен",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GetPriceRequest": {
+            "type": "object",
+            "required": [
+                "coin",
+                "timestamp"
+            ],
+            "properties": {
+                "coin": {
+                    "type": "string"
+                },
+                "convert": {
+                    "description": "Фиатная валюта, в которую пересчитать цену (например EUR)",
+                    "type": "string"
+                },
+                "provenance": {
+                    "description": "Вернуть источник и время получения цены",
+                    "type": "boolean"
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Holding": {
+            "type": "object",
+            "properties": {
+                "coin": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.HoldingRequest": {
+            "type": "object",
+            "required": [
+                "coin"
+            ],
+            "properties": {
+                "coin": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "track": {
+                    "description": "Добавить валюту в список отслеживаемых, если она ещё не отслеживается",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.HoldingValue": {
+            "type": "object",
+            "properties": {
+                "coin": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "quantity": {
+                    "type": "number"
+                },
                 "timestamp": {
+                    "description": "Время использованной цены",
                     "type": "integer"
+                },
+                "value": {
+                    "type": "number"
                 }
             }
         },
-        "models.CoinRequest": {
+        "models.IndexPoint": {
+            "type": "object",
+            "properties": {
+                "level": {
+                    "type": "number"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.IndicatorPoint": {
+            "type": "object",
+            "properties": {
+                "close": {
+                    "type": "number"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "values": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "models.IndicatorSeries": {
             "type": "object",
             "properties": {
                 "coin": {
                     "type": "string"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.IndicatorPoint"
+                    }
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
                 }
             }
         },
-        "models.GetPriceRequest": {
+        "models.Mover": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "description": "Изменение в процентах",
+                    "type": "number"
+                },
+                "coin": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "reference_price": {
+                    "description": "Цена за window до timestamp",
+                    "type": "number"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Movers": {
+            "type": "object",
+            "properties": {
+                "gainers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Mover"
+                    }
+                },
+                "losers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Mover"
+                    }
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "window": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Portfolio": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "integer"
+                },
+                "holdings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Holding"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PortfolioPnL": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "portfolio_id": {
+                    "type": "integer"
+                },
+                "positions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PositionPnL"
+                    }
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "realised_pnl": {
+                    "type": "number"
+                },
+                "unrealised_pnl": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.PortfolioRequest": {
             "type": "object",
             "required": [
-                "coin",
-                "timestamp"
+                "name"
             ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PortfolioValue": {
+            "type": "object",
+            "properties": {
+                "holdings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.HoldingValue"
+                    }
+                },
+                "missing": {
+                    "description": "Позиции без цены на момент оценки (не входят в total)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.PositionPnL": {
+            "type": "object",
+            "properties": {
+                "average_cost": {
+                    "type": "number"
+                },
+                "coin": {
+                    "type": "string"
+                },
+                "cost_basis": {
+                    "type": "number"
+                },
+                "market_price": {
+                    "type": "number"
+                },
+                "market_timestamp": {
+                    "type": "integer"
+                },
+                "market_value": {
+                    "type": "number"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "realised_pnl": {
+                    "type": "number"
+                },
+                "unrealised_pnl": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.PriceHistory": {
+            "type": "object",
+            "properties": {
+                "agg": {
+                    "type": "string"
+                },
+                "coin": {
+                    "type": "string"
+                },
+                "fill": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SeriesPoint"
+                    }
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "step": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Provenance": {
+            "type": "object",
+            "properties": {
+                "ingested_at": {
+                    "description": "Когда коллектор получил цену, null для загруженной истории",
+                    "type": "integer"
+                },
+                "latency": {
+                    "description": "ingested_at - timestamp в миллисекундах",
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "provider_time": {
+                    "description": "Время цены в том виде, в каком его вернул провайдер",
+                    "type": "number"
+                }
+            }
+        },
+        "models.SeriesPoint": {
+            "type": "object",
+            "properties": {
+                "filled": {
+                    "description": "Значение получено заполнением, а не из цен интервала",
+                    "type": "boolean"
+                },
+                "price_time": {
+                    "description": "Время последней цены, из которой получено значение (кроме linear и zero)",
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "value": {
+                    "description": "null, если в интервале нет цен и fill = none",
+                    "type": "number"
+                }
+            }
+        },
+        "models.SnapshotEntry": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "description": "Изменение в процентах за 1h, 24h, 7d; null, если истории не хватает",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "coin": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Transaction": {
+            "type": "object",
             "properties": {
                 "coin": {
                     "type": "string"
                 },
+                "created_at": {
+                    "type": "integer"
+                },
+                "fee": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "portfolio_id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "description": "Цена за единицу в USD",
+                    "type": "number"
+                },
+                "price_time": {
+                    "description": "Время цены из истории, если цена не была указана",
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "number"
+                },
                 "timestamp": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TransactionRequest": {
+            "type": "object",
+            "required": [
+                "coin",
+                "timestamp",
+                "type"
+            ],
+            "properties": {
+                "coin": {
                     "type": "string"
+                },
+                "fee": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "price": {
+                    "description": "Если не указана, берётся из истории цен",
+                    "type": "number"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "buy",
+                        "sell",
+                        "transfer_in",
+                        "transfer_out"
+                    ]
                 }
             }
         }
@@ -0,0 +1,235 @@
+package alerts
+
+import (
+	"context"
+	"crypto_tracker/internal/analytics"
+	"crypto_tracker/internal/models"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+)
+
+const (
+	ConditionAbove = "above"
+	ConditionBelow = "below"
+)
+
+// Состояния правила оповещения
+const (
+	StateOK       = "ok"       // Условие не выполнялось с момента создания правила
+	StateFiring   = "firing"   // Условие выполняется
+	StateResolved = "resolved" // Условие перестало выполняться с учётом гистерезиса
+)
+
+var (
+	DefaultIndicatorInterval = time.Hour // Длина свечи индикатора, если indicator_interval не указан
+	IndicatorWarmup          = 3         // Во сколько раз больше lookback свечей загружается для разгона индикатора
+)
+
+type RuleStorage interface {
+	GetAlertsByCoin(ctx context.Context, coin, quote string) ([]models.Alert, error)
+	TransitionAlert(ctx context.Context, event models.AlertEvent) error
+	GetPrice(ctx context.Context, coin, quote string, timestamp int64) (models.Coin, error)
+	GetCandles(ctx context.Context, coin, quote string, from, to, interval int64) ([]models.Candle, error)
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, notification models.AlertNotification) error
+}
+
+// Evaluator проверяет правила оповещений при каждой новой цене
+type Evaluator struct {
+	log       *slog.Logger
+	storage   RuleStorage
+	notifiers []Notifier
+}
+
+func New(log *slog.Logger, storage RuleStorage, notifiers ...Notifier) *Evaluator {
+	return &Evaluator{
+		log:       log,
+		storage:   storage,
+		notifiers: notifiers,
+	}
+}
+
+// OnPrice вызывается коллектором после сохранения цены
+func (e *Evaluator) OnPrice(ctx context.Context, coin models.Coin) {
+	rules, err := e.storage.GetAlertsByCoin(ctx, coin.Name, coin.Quote)
+	if err != nil {
+		e.log.Error("Failed to get alerts", "coin", coin.Name, "error", err)
+		return
+	}
+
+	for _, rule := range rules {
+		next, err := e.nextState(ctx, rule, coin)
+		if err != nil {
+			e.log.Warn("Failed to evaluate alert", "alert", rule.ID, "coin", coin.Name, "error", err)
+			continue
+		}
+		if next == rule.State {
+			continue
+		}
+
+		now := time.Now().UnixMilli()
+		event := models.AlertEvent{
+			AlertID:   rule.ID,
+			FromState: rule.State,
+			ToState:   next,
+			Price:     coin.Price,
+			Timestamp: coin.Timestamp,
+			Notified:  cooledDown(rule, now),
+			CreatedAt: now,
+		}
+		if err := e.storage.TransitionAlert(ctx, event); err != nil {
+			e.log.Error("Failed to update alert state", "alert", rule.ID, "error", err)
+			continue
+		}
+		e.log.Info("Alert state changed", "alert", rule.ID, "coin", coin.Name, "from", event.FromState,
+			"to", event.ToState, "price", coin.Price, "notified", event.Notified)
+
+		if !event.Notified {
+			continue
+		}
+		notification := models.AlertNotification{
+			AlertID:        rule.ID,
+			State:          next,
+			Coin:           rule.Coin,
+			Quote:          rule.Quote,
+			Condition:      rule.Condition,
+			Threshold:      rule.Price,
+			ChangePercent:  rule.ChangePercent,
+			Window:         rule.Window,
+			Indicator:      rule.Indicator,
+			IndicatorValue: rule.IndicatorValue,
+			Price:          coin.Price,
+			Timestamp:      coin.Timestamp,
+		}
+
+		// Доставка не должна задерживать сбор цен
+		for _, notifier := range e.notifiers {
+			go func() {
+				if err := notifier.Notify(context.WithoutCancel(ctx), notification); err != nil {
+					e.log.Error("Failed to deliver alert", "alert", notification.AlertID, "error", err)
+				}
+			}()
+		}
+	}
+}
+
+// nextState вычисляет новое состояние правила для цены.
+// Сработавшее правило остаётся в firing, пока цена не выйдет за порог с учётом гистерезиса,
+// поэтому колебания цены около порога не приводят к повторным оповещениям.
+func (e *Evaluator) nextState(ctx context.Context, rule models.Alert, coin models.Coin) (string, error) {
+	if rule.State == StateFiring {
+		matched, err := e.matches(ctx, rule, coin, rule.HysteresisPercent)
+		if err != nil || matched {
+			return rule.State, err
+		}
+		return StateResolved, nil
+	}
+
+	matched, err := e.matches(ctx, rule, coin, 0)
+	if err != nil || !matched {
+		return rule.State, err
+	}
+	return StateFiring, nil
+}
+
+// matches проверяет, выполняются ли все условия правила для цены.
+// band смягчает пороги на указанный процент (гистерезис).
+func (e *Evaluator) matches(ctx context.Context, rule models.Alert, coin models.Coin, band float64) (bool, error) {
+	if rule.Price != nil && !crosses(rule.Condition, coin.Price, *rule.Price, band) {
+		return false, nil
+	}
+
+	if rule.ChangePercent != nil {
+		window, err := time.ParseDuration(rule.Window)
+		if err != nil {
+			return false, fmt.Errorf("invalid window %q: %w", rule.Window, err)
+		}
+		past, err := e.storage.GetPrice(ctx, coin.Name, coin.Quote, coin.Timestamp-window.Milliseconds())
+		if err != nil {
+			return false, err
+		}
+		if past.Price == 0 {
+			return false, nil
+		}
+
+		change := (coin.Price - past.Price) / past.Price * 100
+		threshold := *rule.ChangePercent
+		if rule.Condition == ConditionBelow {
+			threshold = -threshold
+		}
+		if !crosses(rule.Condition, change, threshold, band) {
+			return false, nil
+		}
+	}
+
+	if rule.Indicator != "" && rule.IndicatorValue != nil {
+		value, err := e.indicator(ctx, rule, coin)
+		if err != nil {
+			return false, err
+		}
+		// Пока свечей недостаточно, условие индикатора не выполняется
+		if math.IsNaN(value) || !crosses(rule.Condition, value, *rule.IndicatorValue, band) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// indicator вычисляет значение индикатора правила на свече, в которую попадает цена.
+// Для macd используется линия macd.
+func (e *Evaluator) indicator(ctx context.Context, rule models.Alert, coin models.Coin) (float64, error) {
+	interval := DefaultIndicatorInterval
+	if rule.IndicatorInterval != "" {
+		parsed, err := time.ParseDuration(rule.IndicatorInterval)
+		if err != nil || parsed < time.Millisecond {
+			return 0, fmt.Errorf("invalid indicator interval %q", rule.IndicatorInterval)
+		}
+		interval = parsed
+	}
+
+	p := analytics.IndicatorParams{Period: rule.IndicatorPeriod}.WithDefaults(rule.Indicator)
+	step := interval.Milliseconds()
+	from := coin.Timestamp/step*step - int64(IndicatorWarmup*p.Lookback(rule.Indicator))*step
+	candles, err := e.storage.GetCandles(ctx, coin.Name, coin.Quote, from, coin.Timestamp+1, step)
+	if err != nil {
+		return 0, err
+	}
+	if len(candles) == 0 {
+		return math.NaN(), nil
+	}
+
+	closes := make([]float64, len(candles))
+	for i, candle := range candles {
+		closes[i] = candle.Close
+	}
+	series, err := analytics.Indicator(rule.Indicator, closes, p)
+	if err != nil {
+		return 0, err
+	}
+	return series[rule.Indicator][len(closes)-1], nil
+}
+
+func crosses(condition string, value, threshold, band float64) bool {
+	shift := math.Abs(threshold) * band / 100
+	if condition == ConditionBelow {
+		return value <= threshold+shift
+	}
+	return value >= threshold-shift
+}
+
+// cooledDown проверяет, прошла ли минимальная пауза с последнего оповещения
+func cooledDown(rule models.Alert, now int64) bool {
+	if rule.LastNotifiedAt == nil || rule.Cooldown == "" {
+		return true
+	}
+	cooldown, err := time.ParseDuration(rule.Cooldown)
+	if err != nil {
+		return true
+	}
+	return now-*rule.LastNotifiedAt >= cooldown.Milliseconds()
+}
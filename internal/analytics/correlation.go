@@ -0,0 +1,85 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+)
+
+// LogReturns считает логарифмические доходности между соседними точками ряда с шагом step.
+// Ключ - время конца интервала; если предыдущей точки (t - step) нет, доходность для t не считается.
+func LogReturns(timestamps []int64, values []float64, step int64) map[int64]float64 {
+	byTime := make(map[int64]float64, len(timestamps))
+	for i, t := range timestamps {
+		byTime[t] = values[i]
+	}
+
+	returns := make(map[int64]float64, len(timestamps))
+	for t, v := range byTime {
+		prev, ok := byTime[t-step]
+		if ok && prev > 0 && v > 0 {
+			returns[t] = math.Log(v / prev)
+		}
+	}
+	return returns
+}
+
+// Overlap возвращает значения a и b в общие моменты времени в порядке возрастания времени
+func Overlap(a, b map[int64]float64) (x, y []float64) {
+	times := make([]int64, 0, min(len(a), len(b)))
+	for t := range a {
+		if _, ok := b[t]; ok {
+			times = append(times, t)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	x, y = make([]float64, len(times)), make([]float64, len(times))
+	for i, t := range times {
+		x[i], y[i] = a[t], b[t]
+	}
+	return x, y
+}
+
+// Correlation - коэффициент корреляции Пирсона. NaN, если точек меньше двух или один из рядов постоянен.
+func Correlation(x, y []float64) float64 {
+	covariance, varianceX, varianceY := moments(x, y)
+	if varianceX == 0 || varianceY == 0 {
+		return math.NaN()
+	}
+	return covariance / math.Sqrt(varianceX*varianceY)
+}
+
+// Beta - бета ряда y относительно бенчмарка market: cov(y, market) / var(market).
+// NaN, если точек меньше двух или бенчмарк постоянен.
+func Beta(y, market []float64) float64 {
+	covariance, varianceMarket, _ := moments(market, y)
+	if varianceMarket == 0 {
+		return math.NaN()
+	}
+	return covariance / varianceMarket
+}
+
+// moments возвращает выборочные ковариацию x и y и дисперсии x и y
+func moments(x, y []float64) (covariance, varianceX, varianceY float64) {
+	n := len(x)
+	if n < 2 || len(y) != n {
+		return math.NaN(), 0, 0
+	}
+
+	var meanX, meanY float64
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= float64(n)
+	meanY /= float64(n)
+
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		covariance += dx * dy
+		varianceX += dx * dx
+		varianceY += dy * dy
+	}
+	d := float64(n - 1)
+	return covariance / d, varianceX / d, varianceY / d
+}
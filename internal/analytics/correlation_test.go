@@ -0,0 +1,76 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCorrelation(t *testing.T) {
+	tests := []struct {
+		name string
+		x, y []float64
+		want float64
+	}{
+		{"perfect positive", []float64{1, 2, 3, 4}, []float64{2, 4, 6, 8}, 1},
+		{"perfect negative", []float64{1, 2, 3, 4}, []float64{8, 6, 4, 2}, -1},
+		// cov = 1/3, var(x) = 5/3, var(y) = 2/3
+		{"partial", []float64{1, 2, 3, 4}, []float64{1, 3, 2, 2}, (1.0 / 3) / math.Sqrt(10.0/9)},
+		{"constant series", []float64{1, 2, 3}, []float64{5, 5, 5}, nan},
+		{"single point", []float64{1}, []float64{2}, nan},
+		{"length mismatch", []float64{1, 2, 3}, []float64{1, 2}, nan},
+		{"empty", nil, nil, nan},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equalSeries(t, "correlation", []float64{Correlation(tt.x, tt.y)}, []float64{tt.want})
+		})
+	}
+}
+
+func TestBeta(t *testing.T) {
+	tests := []struct {
+		name      string
+		y, market []float64
+		want      float64
+	}{
+		{"double the market", []float64{2, 4, 6, 8}, []float64{1, 2, 3, 4}, 2},
+		{"inverse", []float64{-1, -2, -3}, []float64{1, 2, 3}, -1},
+		// cov = 1/3, var(market) = 5/3
+		{"partial", []float64{1, 3, 2, 2}, []float64{1, 2, 3, 4}, 0.2},
+		{"constant asset", []float64{5, 5, 5}, []float64{1, 2, 3}, 0},
+		{"constant market", []float64{1, 2, 3}, []float64{5, 5, 5}, nan},
+		{"single point", []float64{1}, []float64{2}, nan},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equalSeries(t, "beta", []float64{Beta(tt.y, tt.market)}, []float64{tt.want})
+		})
+	}
+}
+
+func TestLogReturns(t *testing.T) {
+	timestamps := []int64{0, 10, 20, 40}
+	values := []float64{100, 110, 121, 150}
+	returns := LogReturns(timestamps, values, 10)
+
+	want := map[int64]float64{10: math.Log(1.1), 20: math.Log(1.1)}
+	if len(returns) != len(want) {
+		t.Fatalf("LogReturns returned %d points, want %d: %v", len(returns), len(want), returns)
+	}
+	for ts, value := range want {
+		if math.Abs(returns[ts]-value) > tolerance {
+			t.Errorf("return at %d = %v, want %v", ts, returns[ts], value)
+		}
+	}
+	if _, ok := returns[40]; ok {
+		t.Error("return at 40 computed without a price at 30")
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	a := map[int64]float64{3: 0.3, 1: 0.1, 2: 0.2}
+	b := map[int64]float64{2: 2, 3: 3, 4: 4}
+	x, y := Overlap(a, b)
+	equalSeries(t, "x", x, []float64{0.2, 0.3})
+	equalSeries(t, "y", y, []float64{2, 3})
+}
@@ -0,0 +1,199 @@
+package analytics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Типы индикаторов
+const (
+	IndicatorSMA       = "sma"
+	IndicatorEMA       = "ema"
+	IndicatorRSI       = "rsi"
+	IndicatorBollinger = "bollinger"
+	IndicatorMACD      = "macd"
+)
+
+var ErrUnknownIndicator = errors.New("unknown indicator")
+
+// IndicatorParams - параметры индикатора. Нулевые значения заменяются стандартными
+// (20 для sma, ema и bollinger, 14 для rsi, 12/26/9 для macd, 2 стандартных отклонения для bollinger).
+type IndicatorParams struct {
+	Period int
+	Fast   int
+	Slow   int
+	Signal int
+	K      float64
+}
+
+// WithDefaults заполняет незаданные параметры стандартными значениями для индикатора kind
+func (p IndicatorParams) WithDefaults(kind string) IndicatorParams {
+	if p.Period <= 0 {
+		p.Period = 20
+		if kind == IndicatorRSI {
+			p.Period = 14
+		}
+	}
+	if p.Fast <= 0 {
+		p.Fast = 12
+	}
+	if p.Slow <= 0 {
+		p.Slow = 26
+	}
+	if p.Signal <= 0 {
+		p.Signal = 9
+	}
+	if p.K <= 0 {
+		p.K = 2
+	}
+	return p
+}
+
+// Lookback - число значений, после которого индикатор kind определён
+func (p IndicatorParams) Lookback(kind string) int {
+	if kind == IndicatorMACD {
+		return p.Slow + p.Signal - 1
+	}
+	return p.Period
+}
+
+// Indicator вычисляет индикатор kind по ряду values. Возвращает именованные ряды той же длины, что values:
+// sma, ema, rsi - один ряд с именем индикатора; bollinger - middle, upper, lower; macd - macd, signal, histogram.
+// Значения, для которых данных ещё недостаточно, равны NaN.
+func Indicator(kind string, values []float64, p IndicatorParams) (map[string][]float64, error) {
+	p = p.WithDefaults(kind)
+	switch kind {
+	case IndicatorSMA:
+		return map[string][]float64{kind: SMA(values, p.Period)}, nil
+	case IndicatorEMA:
+		return map[string][]float64{kind: EMA(values, p.Period)}, nil
+	case IndicatorRSI:
+		return map[string][]float64{kind: RSI(values, p.Period)}, nil
+	case IndicatorBollinger:
+		middle, upper, lower := Bollinger(values, p.Period, p.K)
+		return map[string][]float64{"middle": middle, "upper": upper, "lower": lower}, nil
+	case IndicatorMACD:
+		macd, signal, histogram := MACD(values, p.Fast, p.Slow, p.Signal)
+		return map[string][]float64{"macd": macd, "signal": signal, "histogram": histogram}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownIndicator, kind)
+}
+
+// SMA - простое скользящее среднее за period значений
+func SMA(values []float64, period int) []float64 {
+	result := nans(len(values))
+	var sum float64
+	for i, v := range values {
+		sum += v
+		if i >= period {
+			sum -= values[i-period]
+		}
+		if i >= period-1 {
+			result[i] = sum / float64(period)
+		}
+	}
+	return result
+}
+
+// EMA - экспоненциальное скользящее среднее с коэффициентом 2/(period+1).
+// Первое значение - SMA первых period значений. NaN во входном ряду пропускаются до начала расчёта.
+func EMA(values []float64, period int) []float64 {
+	result := nans(len(values))
+	alpha := 2 / float64(period+1)
+
+	start := 0
+	for start < len(values) && math.IsNaN(values[start]) {
+		start++
+	}
+	if len(values)-start < period {
+		return result
+	}
+
+	var sum float64
+	for i := start; i < start+period; i++ {
+		sum += values[i]
+	}
+	prev := sum / float64(period)
+	result[start+period-1] = prev
+	for i := start + period; i < len(values); i++ {
+		prev = alpha*values[i] + (1-alpha)*prev
+		result[i] = prev
+	}
+	return result
+}
+
+// RSI - индекс относительной силы Уайлдера за period изменений (0..100)
+func RSI(values []float64, period int) []float64 {
+	result := nans(len(values))
+	if len(values) <= period {
+		return result
+	}
+
+	var gain, loss float64
+	for i := 1; i <= period; i++ {
+		change := values[i] - values[i-1]
+		gain += max(change, 0)
+		loss += max(-change, 0)
+	}
+	gain /= float64(period)
+	loss /= float64(period)
+	result[period] = rsi(gain, loss)
+
+	for i := period + 1; i < len(values); i++ {
+		change := values[i] - values[i-1]
+		gain = (gain*float64(period-1) + max(change, 0)) / float64(period)
+		loss = (loss*float64(period-1) + max(-change, 0)) / float64(period)
+		result[i] = rsi(gain, loss)
+	}
+	return result
+}
+
+func rsi(gain, loss float64) float64 {
+	if loss == 0 {
+		if gain == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+gain/loss)
+}
+
+// Bollinger - полосы Боллинджера: SMA за period и SMA ± k стандартных отклонений (по генеральной совокупности)
+func Bollinger(values []float64, period int, k float64) (middle, upper, lower []float64) {
+	middle = SMA(values, period)
+	upper, lower = nans(len(values)), nans(len(values))
+	for i := period - 1; i < len(values); i++ {
+		var variance float64
+		for _, v := range values[i-period+1 : i+1] {
+			variance += (v - middle[i]) * (v - middle[i])
+		}
+		deviation := math.Sqrt(variance / float64(period))
+		upper[i] = middle[i] + k*deviation
+		lower[i] = middle[i] - k*deviation
+	}
+	return middle, upper, lower
+}
+
+// MACD - разность EMA(fast) и EMA(slow), сигнальная линия EMA(signal) от неё и гистограмма
+func MACD(values []float64, fast, slow, signal int) (macd, signalLine, histogram []float64) {
+	fastEMA, slowEMA := EMA(values, fast), EMA(values, slow)
+	macd = nans(len(values))
+	for i := range values {
+		macd[i] = fastEMA[i] - slowEMA[i]
+	}
+	signalLine = EMA(macd, signal)
+	histogram = nans(len(values))
+	for i := range values {
+		histogram[i] = macd[i] - signalLine[i]
+	}
+	return macd, signalLine, histogram
+}
+
+func nans(n int) []float64 {
+	result := make([]float64, n)
+	for i := range result {
+		result[i] = math.NaN()
+	}
+	return result
+}
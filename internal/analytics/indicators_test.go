@@ -0,0 +1,231 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+)
+
+const tolerance = 1e-9
+
+func equalSeries(t *testing.T, name string, got, want []float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: len = %d, want %d", name, len(got), len(want))
+	}
+	for i := range want {
+		if math.IsNaN(want[i]) {
+			if !math.IsNaN(got[i]) {
+				t.Errorf("%s[%d] = %v, want NaN", name, i, got[i])
+			}
+			continue
+		}
+		if math.Abs(got[i]-want[i]) > tolerance {
+			t.Errorf("%s[%d] = %v, want %v", name, i, got[i], want[i])
+		}
+	}
+}
+
+// firstDefined возвращает индекс первого значения, не равного NaN, или -1
+func firstDefined(values []float64) int {
+	for i, v := range values {
+		if !math.IsNaN(v) {
+			return i
+		}
+	}
+	return -1
+}
+
+func series(n int, value func(i int) float64) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = value(i)
+	}
+	return values
+}
+
+var nan = math.NaN()
+
+func TestSMA(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		period int
+		want   []float64
+	}{
+		{"warm-up", []float64{1, 2, 3, 4, 5}, 3, []float64{nan, nan, 2, 3, 4}},
+		{"period equals input", []float64{2, 4, 6}, 3, []float64{nan, nan, 4}},
+		{"period longer than input", []float64{1, 2}, 3, []float64{nan, nan}},
+		{"period one", []float64{5, 7}, 1, []float64{5, 7}},
+		{"empty", nil, 3, []float64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equalSeries(t, "sma", SMA(tt.values, tt.period), tt.want)
+		})
+	}
+}
+
+func TestEMA(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		period int
+		want   []float64
+	}{
+		// alpha = 2/(3+1) = 0.5, первое значение - SMA(1, 2, 3) = 2
+		{"warm-up", []float64{1, 2, 3, 4, 5}, 3, []float64{nan, nan, 2, 3, 4}},
+		{"alpha", []float64{2, 4, 6, 12}, 3, []float64{nan, nan, 4, 8}},
+		{"leading NaN skipped", []float64{nan, nan, 1, 2, 3, 4}, 3, []float64{nan, nan, nan, nan, 2, 3}},
+		{"period longer than input", []float64{1, 2}, 3, []float64{nan, nan}},
+		{"too short after NaN", []float64{nan, 1, 2}, 3, []float64{nan, nan, nan}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equalSeries(t, "ema", EMA(tt.values, tt.period), tt.want)
+		})
+	}
+}
+
+func TestRSI(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		period int
+		want   []float64
+	}{
+		{"all gains", []float64{1, 2, 3, 4, 5}, 3, []float64{nan, nan, nan, 100, 100}},
+		{"all losses", []float64{5, 4, 3, 2, 1}, 3, []float64{nan, nan, nan, 0, 0}},
+		{"flat", []float64{3, 3, 3, 3}, 2, []float64{nan, nan, 50, 50}},
+		// Средний рост 2/3, среднее падение 1/3: RSI = 100 - 100/(1+2) = 66.67.
+		// Затем по Уайлдеру: рост (2/3*2+0)/3 = 4/9, падение (1/3*2+2)/3 = 8/9, RSI = 100 - 100/(1+0.5)
+		{"wilder smoothing", []float64{1, 2, 1, 2, 0}, 3, []float64{nan, nan, nan, 100 - 100.0/3, 100 - 100/1.5}},
+		{"period equals input", []float64{1, 2, 3}, 3, []float64{nan, nan, nan}},
+		{"period longer than input", []float64{1, 2}, 3, []float64{nan, nan}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equalSeries(t, "rsi", RSI(tt.values, tt.period), tt.want)
+		})
+	}
+}
+
+func TestBollinger(t *testing.T) {
+	tests := []struct {
+		name                             string
+		values                           []float64
+		period                           int
+		k                                float64
+		wantMiddle, wantUpper, wantLower []float64
+	}{
+		// Окно (2, 4, 6): среднее 4, стандартное отклонение sqrt(8/3)
+		{
+			"warm-up", []float64{2, 4, 6, 6}, 3, 2,
+			[]float64{nan, nan, 4, 16.0 / 3},
+			[]float64{nan, nan, 4 + 2*math.Sqrt(8.0/3), 16.0/3 + 2*math.Sqrt(8.0/9)},
+			[]float64{nan, nan, 4 - 2*math.Sqrt(8.0/3), 16.0/3 - 2*math.Sqrt(8.0/9)},
+		},
+		{
+			"constant", []float64{5, 5, 5}, 2, 2,
+			[]float64{nan, 5, 5}, []float64{nan, 5, 5}, []float64{nan, 5, 5},
+		},
+		{
+			"period longer than input", []float64{1, 2}, 3, 2,
+			[]float64{nan, nan}, []float64{nan, nan}, []float64{nan, nan},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middle, upper, lower := Bollinger(tt.values, tt.period, tt.k)
+			equalSeries(t, "middle", middle, tt.wantMiddle)
+			equalSeries(t, "upper", upper, tt.wantUpper)
+			equalSeries(t, "lower", lower, tt.wantLower)
+		})
+	}
+}
+
+func TestMACD(t *testing.T) {
+	tests := []struct {
+		name                 string
+		n                    int
+		fast, slow, signal   int
+		wantMACD, wantSignal int // Индекс первого определённого значения, -1 если их нет
+	}{
+		{"defaults", 60, 12, 26, 9, 25, 33},
+		{"short periods", 10, 2, 3, 2, 2, 3},
+		{"signal longer than macd", 28, 12, 26, 9, 25, -1},
+		{"slow longer than input", 20, 12, 26, 9, -1, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := series(tt.n, func(i int) float64 { return 100 + 10*math.Sin(float64(i)/3) })
+			macd, signal, histogram := MACD(values, tt.fast, tt.slow, tt.signal)
+
+			if got := firstDefined(macd); got != tt.wantMACD {
+				t.Errorf("first macd index = %d, want %d", got, tt.wantMACD)
+			}
+			if got := firstDefined(signal); got != tt.wantSignal {
+				t.Errorf("first signal index = %d, want %d", got, tt.wantSignal)
+			}
+			if got := firstDefined(histogram); got != tt.wantSignal {
+				t.Errorf("first histogram index = %d, want %d", got, tt.wantSignal)
+			}
+
+			fastEMA, slowEMA := EMA(values, tt.fast), EMA(values, tt.slow)
+			for i := range values {
+				if math.IsNaN(macd[i]) {
+					continue
+				}
+				if want := fastEMA[i] - slowEMA[i]; math.Abs(macd[i]-want) > tolerance {
+					t.Errorf("macd[%d] = %v, want %v", i, macd[i], want)
+				}
+				if !math.IsNaN(histogram[i]) && math.Abs(histogram[i]-(macd[i]-signal[i])) > tolerance {
+					t.Errorf("histogram[%d] = %v, want %v", i, histogram[i], macd[i]-signal[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMACDLookback(t *testing.T) {
+	// Lookback должен совпадать с числом значений, после которого определена сигнальная линия
+	p := IndicatorParams{}.WithDefaults(IndicatorMACD)
+	values := series(100, func(i int) float64 { return float64(i % 7) })
+	_, signal, _ := MACD(values, p.Fast, p.Slow, p.Signal)
+	if got, want := firstDefined(signal)+1, p.Lookback(IndicatorMACD); got != want {
+		t.Errorf("signal defined after %d values, lookback = %d", got, want)
+	}
+}
+
+func TestIndicator(t *testing.T) {
+	values := series(40, func(i int) float64 { return float64(i) })
+	tests := []struct {
+		kind  string
+		names []string
+	}{
+		{IndicatorSMA, []string{"sma"}},
+		{IndicatorEMA, []string{"ema"}},
+		{IndicatorRSI, []string{"rsi"}},
+		{IndicatorBollinger, []string{"middle", "upper", "lower"}},
+		{IndicatorMACD, []string{"macd", "signal", "histogram"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			result, err := Indicator(tt.kind, values, IndicatorParams{})
+			if err != nil {
+				t.Fatalf("Indicator(%q) error = %v", tt.kind, err)
+			}
+			if len(result) != len(tt.names) {
+				t.Errorf("Indicator(%q) returned %d series, want %d", tt.kind, len(result), len(tt.names))
+			}
+			for _, name := range tt.names {
+				if len(result[name]) != len(values) {
+					t.Errorf("series %q len = %d, want %d", name, len(result[name]), len(values))
+				}
+			}
+		})
+	}
+
+	if _, err := Indicator("vwap", values, IndicatorParams{}); err == nil {
+		t.Error("Indicator(\"vwap\") error = nil, want ErrUnknownIndicator")
+	}
+}
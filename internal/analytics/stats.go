@@ -0,0 +1,25 @@
+package analytics
+
+import (
+	"math"
+	"time"
+)
+
+// Year - длина года для пересчёта волатильности (крипторынок торгуется круглосуточно)
+const Year = 365 * 24 * time.Hour
+
+// PercentChange возвращает изменение from -> to в процентах; при from = 0 - 0
+func PercentChange(from, to float64) float64 {
+	if from == 0 {
+		return 0
+	}
+	return (to - from) / from * 100
+}
+
+// AnnualisedVolatility пересчитывает стандартное отклонение доходностей за интервал interval в годовое
+func AnnualisedVolatility(returnsStdDev float64, interval time.Duration) float64 {
+	if interval <= 0 {
+		return 0
+	}
+	return returnsStdDev * math.Sqrt(float64(Year)/float64(interval))
+}
@@ -0,0 +1,190 @@
+package backfill
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/tracker"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// MaxAttempts - сколько раз запрашивается часть истории, прежде чем задача завершится ошибкой
+const MaxAttempts = 3
+
+var ErrInvalidPeriod = errors.New("backfill_from must be a past timestamp in milliseconds")
+
+type Storage interface {
+	CreateBackfillJob(ctx context.Context, job models.BackfillJob) (int64, error)
+	GetPendingBackfillJobs(ctx context.Context) ([]models.BackfillJob, error)
+	UpdateBackfillJob(ctx context.Context, job models.BackfillJob) error
+	AddCoins(ctx context.Context, coins []models.Coin, source string) (int64, error)
+}
+
+// HistoryProvider - источник исторических цен (тот же market/history, что использует коллектор).
+// Цены возвращаются под названием валюты у провайдера, под которым их сохраняет и коллектор,
+// поэтому загруженная история попадает в тот же ряд, что и собранные цены.
+type HistoryProvider interface {
+	FetchHistory(ctx context.Context, coin, quote string, from, to int64) ([]models.Coin, error)
+}
+
+// Runner выполняет задачи загрузки истории по одной: период задачи запрашивается у провайдера частями
+// длиной chunk, не чаще одного запроса в rate. Позиция сохраняется после каждой части, поэтому
+// незавершённые задачи продолжаются с того же места после перезапуска.
+type Runner struct {
+	log      *slog.Logger
+	storage  Storage
+	provider HistoryProvider
+	chunk    time.Duration
+	rate     time.Duration
+
+	wake chan struct{}
+}
+
+func New(log *slog.Logger, storage Storage, provider HistoryProvider, chunk, rate time.Duration) *Runner {
+	return &Runner{
+		log:      log,
+		storage:  storage,
+		provider: provider,
+		chunk:    chunk,
+		rate:     rate,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Enqueue создаёт задачу загрузки истории coin/quote с from до текущего времени
+func (r *Runner) Enqueue(ctx context.Context, coin, quote string, from int64) (models.BackfillJob, error) {
+	now := time.Now().UnixMilli()
+	if from <= 0 || from >= now {
+		return models.BackfillJob{}, ErrInvalidPeriod
+	}
+
+	job := models.BackfillJob{
+		Coin:      coin,
+		Quote:     quote,
+		From:      from,
+		To:        now,
+		Cursor:    from,
+		Status:    models.JobQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	id, err := r.storage.CreateBackfillJob(ctx, job)
+	if err != nil {
+		return models.BackfillJob{}, err
+	}
+	job.ID = id
+
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Run выполняет незавершённые задачи (в том числе оставшиеся с прошлого запуска) и ждёт новые до отмены ctx
+func (r *Runner) Run(ctx context.Context) {
+	limiter := time.NewTicker(r.rate)
+	defer limiter.Stop()
+
+	for {
+		jobs, err := r.storage.GetPendingBackfillJobs(ctx)
+		if err != nil {
+			r.log.Error("Failed to get backfill jobs", "error", err)
+		}
+		for _, job := range jobs {
+			if !r.process(ctx, job, limiter.C) {
+				r.log.Info("Stopped backfill")
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			r.log.Info("Stopped backfill")
+			return
+		case <-r.wake:
+		}
+	}
+}
+
+// process загружает историю задачи с сохранённой позиции. Возвращает false, если ctx отменён:
+// задача остаётся в статусе running и продолжится при следующем запуске.
+func (r *Runner) process(ctx context.Context, job models.BackfillJob, limiter <-chan time.Time) bool {
+	log := r.log.With("job", job.ID, "coin", job.Coin, "quote", job.Quote)
+	log.Info("Backfill started", "from", job.Cursor, "to", job.To)
+
+	job.Status = models.JobRunning
+	r.save(ctx, log, &job)
+
+	for job.Cursor < job.To {
+		end := min(job.Cursor+r.chunk.Milliseconds(), job.To)
+
+		var prices []models.Coin
+		var err error
+		var retryAfter time.Duration
+		for attempt := 1; attempt <= MaxAttempts; attempt++ {
+			// Если провайдер ограничил частоту запросов и сообщил, когда повторить, ждём не меньше этого
+			if retryAfter > 0 {
+				select {
+				case <-ctx.Done():
+					return false
+				case <-time.After(retryAfter):
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return false
+			case <-limiter:
+			}
+			prices, err = r.provider.FetchHistory(ctx, job.Coin, job.Quote, job.Cursor, end)
+			if err == nil {
+				break
+			}
+			retryAfter = 0
+			var providerErr *tracker.ProviderError
+			if errors.As(err, &providerErr) && errors.Is(err, tracker.ErrRateLimited) {
+				retryAfter = providerErr.RetryAfter
+			}
+			log.Warn("Backfill request failed", "from", job.Cursor, "to", end, "attempt", attempt,
+				"kind", tracker.ErrorKind(err), "retry_after", retryAfter, "error", err)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return false
+			}
+			job.Status, job.Error = models.JobFailed, err.Error()
+			r.save(ctx, log, &job)
+			log.Error("Backfill failed", "error", err)
+			return true
+		}
+
+		if len(prices) > 0 {
+			added, err := r.storage.AddCoins(ctx, prices, models.SourceBackfill)
+			if err != nil {
+				if ctx.Err() != nil {
+					return false
+				}
+				job.Status, job.Error = models.JobFailed, err.Error()
+				r.save(ctx, log, &job)
+				log.Error("Backfill failed", "error", err)
+				return true
+			}
+			job.Inserted += added
+		}
+		job.Cursor = end
+		r.save(ctx, log, &job)
+	}
+
+	job.Status = models.JobDone
+	r.save(ctx, log, &job)
+	log.Info("Backfill done", "inserted", job.Inserted)
+	return true
+}
+
+func (r *Runner) save(ctx context.Context, log *slog.Logger, job *models.BackfillJob) {
+	job.UpdatedAt = time.Now().UnixMilli()
+	if err := r.storage.UpdateBackfillJob(ctx, *job); err != nil {
+		log.Error("Failed to save backfill job", "error", err)
+	}
+}
@@ -0,0 +1,112 @@
+package fx
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Frankfurter - провайдер дневных курсов ЕЦБ (https://www.frankfurter.app)
+type Frankfurter struct {
+	apiURL string
+	client *http.Client
+}
+
+func NewFrankfurter(apiURL string) *Frankfurter {
+	return &Frankfurter{
+		apiURL: strings.TrimRight(apiURL, "/"),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (f *Frankfurter) Latest(ctx context.Context, base string, symbols []string) ([]models.FXRate, error) {
+	const op = "fx.Frankfurter.Latest"
+
+	var responseAPI struct {
+		Base  string             `json:"base"`
+		Date  string             `json:"date"`
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := f.get(ctx, "/latest", base, symbols, &responseAPI); err != nil {
+		return nil, fmt.Errorf("%s; %w", op, err)
+	}
+
+	rates, err := dayRates(responseAPI.Base, responseAPI.Date, responseAPI.Rates)
+	if err != nil {
+		return nil, fmt.Errorf("%s; %w", op, err)
+	}
+	return rates, nil
+}
+
+// History возвращает дневные курсы base -> symbols за дни публикации с from по to (эндпоинт /{start}..{end})
+func (f *Frankfurter) History(ctx context.Context, base string, symbols []string, from, to time.Time) ([]models.FXRate, error) {
+	const op = "fx.Frankfurter.History"
+
+	var responseAPI struct {
+		Base  string                        `json:"base"`
+		Rates map[string]map[string]float64 `json:"rates"`
+	}
+	path := "/" + from.UTC().Format(time.DateOnly) + ".." + to.UTC().Format(time.DateOnly)
+	if err := f.get(ctx, path, base, symbols, &responseAPI); err != nil {
+		return nil, fmt.Errorf("%s; %w", op, err)
+	}
+
+	rates := make([]models.FXRate, 0, len(responseAPI.Rates)*len(symbols))
+	for day, dayQuotes := range responseAPI.Rates {
+		dayRates, err := dayRates(responseAPI.Base, day, dayQuotes)
+		if err != nil {
+			return nil, fmt.Errorf("%s; %w", op, err)
+		}
+		rates = append(rates, dayRates...)
+	}
+	return rates, nil
+}
+
+func (f *Frankfurter) get(ctx context.Context, path, base string, symbols []string, dst any) error {
+	query := url.Values{}
+	query.Set("from", base)
+	query.Set("to", strings.Join(symbols, ","))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.apiURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// dayRates преобразует курсы, опубликованные в день date, в models.FXRate
+func dayRates(base, date string, quotes map[string]float64) ([]models.FXRate, error) {
+	// Курс действует с начала дня публикации (UTC)
+	day, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q: %w", date, err)
+	}
+
+	rates := make([]models.FXRate, 0, len(quotes))
+	for quote, rate := range quotes {
+		rates = append(rates, models.FXRate{
+			Base:      base,
+			Quote:     quote,
+			Rate:      rate,
+			Timestamp: day.UnixMilli(),
+		})
+	}
+	return rates, nil
+}
@@ -0,0 +1,104 @@
+package fx
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"log/slog"
+	"time"
+)
+
+// Provider - источник курсов фиатных валют
+type Provider interface {
+	// Latest возвращает текущие курсы base -> symbols
+	Latest(ctx context.Context, base string, symbols []string) ([]models.FXRate, error)
+	// History возвращает курсы base -> symbols, опубликованные с from по to
+	History(ctx context.Context, base string, symbols []string, from, to time.Time) ([]models.FXRate, error)
+}
+
+// HistoryChunk - длина периода, за который курсы запрашиваются у провайдера за один раз
+var HistoryChunk = 90 * 24 * time.Hour
+
+type RateSaver interface {
+	AddFXRates(ctx context.Context, rates []models.FXRate) error
+}
+
+// Collector периодически сохраняет курсы фиатных валют относительно USD
+type Collector struct {
+	log      *slog.Logger
+	provider Provider
+	storage  RateSaver
+	symbols  []string
+	interval time.Duration
+	history  time.Duration
+}
+
+// NewCollector создаёт коллектор курсов. history - за какой период курсы загружаются при запуске,
+// чтобы конвертация цен из прошлого не зависела от того, когда сервис впервые собрал курсы.
+func NewCollector(log *slog.Logger, provider Provider, storage RateSaver, symbols []string,
+	interval, history time.Duration) *Collector {
+	return &Collector{
+		log:      log,
+		provider: provider,
+		storage:  storage,
+		symbols:  symbols,
+		interval: interval,
+		history:  history,
+	}
+}
+
+// Run загружает курсы за history, затем собирает текущие курсы сразу и раз в interval до отмены ctx
+func (c *Collector) Run(ctx context.Context) {
+	c.backfill(ctx)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.collect(ctx)
+
+		select {
+		case <-ctx.Done():
+			c.log.Info("Stopped fx rates collection")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Collector) collect(ctx context.Context) {
+	rates, err := c.provider.Latest(ctx, models.DefaultQuote, c.symbols)
+	if err != nil {
+		c.log.Warn("Failed to fetch fx rates", "symbols", c.symbols, "error", err)
+		return
+	}
+	if err := c.storage.AddFXRates(ctx, rates); err != nil {
+		c.log.Error("Failed to save fx rates", "error", err)
+		return
+	}
+	c.log.Debug("Fx rates saved", "count", len(rates))
+}
+
+// backfill сохраняет курсы за последние history частями по HistoryChunk.
+// Уже сохранённые курсы перезаписываются, поэтому загрузка при каждом запуске безопасна.
+func (c *Collector) backfill(ctx context.Context) {
+	to := time.Now().UTC()
+	for from := to.Add(-c.history); from.Before(to); from = from.Add(HistoryChunk) {
+		end := from.Add(HistoryChunk)
+		if end.After(to) {
+			end = to
+		}
+		rates, err := c.provider.History(ctx, models.DefaultQuote, c.symbols, from, end)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			c.log.Warn("Failed to fetch fx rates history", "symbols", c.symbols, "from", from, "to", end, "error", err)
+			continue
+		}
+		if err := c.storage.AddFXRates(ctx, rates); err != nil {
+			c.log.Error("Failed to save fx rates history", "error", err)
+			return
+		}
+		c.log.Debug("Fx rates history saved", "from", from, "to", end, "count", len(rates))
+	}
+}
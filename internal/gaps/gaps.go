@@ -0,0 +1,125 @@
+package gaps
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/tracker"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type Storage interface {
+	GetGaps(ctx context.Context, coin, quote string, from, to, threshold int64) ([]models.Gap, error)
+	AddCoins(ctx context.Context, coins []models.Coin, source string) (int64, error)
+}
+
+// HistoryProvider - источник исторических цен (тот же market/history, что использует коллектор).
+// Name возвращает название, под которым сохраняются цены валюты.
+type HistoryProvider interface {
+	FetchHistory(ctx context.Context, coin, quote string, from, to int64) ([]models.Coin, error)
+	Name(coin string) string
+}
+
+// Filler периодически ищет пропуски в ценах отслеживаемых валют и дозапрашивает их у провайдера
+type Filler struct {
+	log       *slog.Logger
+	storage   Storage
+	provider  HistoryProvider
+	threshold time.Duration // Минимальная длина пропуска
+	lookback  time.Duration // Насколько далеко в прошлое проверяются цены
+
+	mu    sync.Mutex
+	scans map[string]models.GapScan // Результаты последней проверки по ключу tracker.Key
+}
+
+func NewFiller(log *slog.Logger, storage Storage, provider HistoryProvider, threshold, lookback time.Duration) *Filler {
+	return &Filler{
+		log:       log,
+		storage:   storage,
+		provider:  provider,
+		threshold: threshold,
+		lookback:  lookback,
+		scans:     make(map[string]models.GapScan),
+	}
+}
+
+// Threshold возвращает минимальную длину промежутка между ценами, который считается пропуском
+func (f *Filler) Threshold() time.Duration {
+	return f.threshold
+}
+
+// Run проверяет пропуски раз в interval до отмены ctx
+func (f *Filler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			f.log.Info("Stopped gap backfill")
+			return
+		case <-ticker.C:
+			f.Scan(ctx)
+		}
+	}
+}
+
+// Scan проверяет все отслеживаемые валюты за последние lookback
+func (f *Filler) Scan(ctx context.Context) {
+	to := time.Now().UnixMilli()
+	from := to - f.lookback.Milliseconds()
+
+	for _, pair := range tracker.Tracked() {
+		scan := f.scan(ctx, pair, from, to)
+		if scan.Error != "" {
+			f.log.Warn("Gap backfill failed", "coin", pair.Coin, "quote", pair.Quote, "error", scan.Error)
+		} else if scan.Gaps > 0 {
+			f.log.Info("Gaps backfilled", "coin", pair.Coin, "quote", pair.Quote, "gaps", scan.Gaps,
+				"recovered", scan.Recovered)
+		}
+
+		f.mu.Lock()
+		f.scans[tracker.Key(pair.Coin, pair.Quote)] = scan
+		f.mu.Unlock()
+	}
+}
+
+// LastScan возвращает результат последней проверки пары coin/quote
+func (f *Filler) LastScan(coin, quote string) (models.GapScan, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	scan, ok := f.scans[tracker.Key(coin, quote)]
+	return scan, ok
+}
+
+func (f *Filler) scan(ctx context.Context, pair tracker.Pair, from, to int64) models.GapScan {
+	scan := models.GapScan{At: time.Now().UnixMilli()}
+
+	// Пропуски ищутся в ряду, куда коллектор сохраняет цены, а не под названием, под которым валюту добавили
+	gaps, err := f.storage.GetGaps(ctx, f.provider.Name(pair.Coin), pair.Quote, from, to, f.threshold.Milliseconds())
+	if err != nil {
+		scan.Error = err.Error()
+		return scan
+	}
+	scan.Gaps = len(gaps)
+
+	for _, gap := range gaps {
+		prices, err := f.provider.FetchHistory(ctx, pair.Coin, pair.Quote, gap.From+1, gap.To-1)
+		if err != nil {
+			scan.Error = fmt.Sprintf("gap %d-%d: %s", gap.From, gap.To, err)
+			return scan
+		}
+		if len(prices) == 0 {
+			continue
+		}
+		added, err := f.storage.AddCoins(ctx, prices, models.SourceGapFill)
+		if err != nil {
+			scan.Error = err.Error()
+			return scan
+		}
+		scan.Recovered += added
+	}
+	return scan
+}
@@ -2,38 +2,54 @@ package add
 
 import (
 	"context"
+	"crypto_tracker/internal/backfill"
 	"crypto_tracker/internal/models"
 	"crypto_tracker/internal/tracker"
 	"encoding/json"
-	"fmt"
-	"log"
+	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/render"
 )
 
-var (
-	N = 10 // Сколько секунд ждать перед следующим считыванием валюты
-)
+// DefaultRetryAfter - через сколько секунд советовать повторить запрос, если провайдер не передал Retry-After
+var DefaultRetryAfter = 30 * time.Second
+
+type Collector interface {
+	Watch(ctx context.Context, coin, quote string) error
+	WatchPending(ctx context.Context, coin, quote string) error
+}
 
-type AddNewCoin interface {
-	AddCoin(ctx context.Context, coin models.Coin) error
+type Backfiller interface {
+	Enqueue(ctx context.Context, coin, quote string, from int64) (models.BackfillJob, error)
 }
 
 // @Summary Добавить криптовалюту для отслеживания
-// @Description Добавляет криптовалюту в список отслеживаемых и начинает сбор данных о её цене.
+// @Description Добавляет криптовалюту в список отслеживаемых и начинает сбор данных о её цене в валюте котировки quote (по умолчанию USD).
+// @Description Если передан backfill_from, создаётся задача загрузки истории цен с этого времени; её состояние доступно по GET /jobs/{id}.
+// @Description Если задачу создать не удалось, валюта всё равно добавляется, а в ответе возвращается backfill_error.
+// @Description Если провайдер недоступен и allow_pending = true, валюта добавляется в состоянии pending_validation (ответ 202):
+// @Description проверка повторяется в фоне, после неё начинается сбор цен.
 // @ID add-coin
 // @Accept json
 // @Produce json
 // @Param request body models.CoinRequest true "Данные для добавления криптовалюты"
-// @Success 200 {object} map[string]string "message: Currency added to watchlist"
+// @Success 200 {object} models.AddCoinResponse "message: Currency added to watchlist"
+// @Success 202 {object} models.AddCoinResponse "message: Currency pending validation"
 // @Failure 400 {object} map[string]string "error: Invalid request body"
-// @Failure 400 {object} map[string]string "error: Invalid coin"
+// @Failure 400 {object} map[string]string "error: Invalid quote"
+// @Failure 400 {object} map[string]string "error: backfill_from must be a past timestamp in milliseconds"
+// @Failure 400 {object} map[string]string "error: Unknown asset"
 // @Failure 400 {object} map[string]string "error: Coin is already being tracked"
+// @Failure 500 {object} map[string]string "error: Provider misconfigured"
+// @Failure 500 {object} map[string]string "error: Failed to add currency"
+// @Failure 502 {object} map[string]string "error: Provider unavailable, retry_after: секунды"
+// @Failure 503 {object} map[string]string "error: Provider rate limit exceeded, retry_after: секунды"
 // @Router /currency/add [post]
-func New(log *slog.Logger, apiURL string, apiKey string, addNewCoin AddNewCoin) http.HandlerFunc {
+func New(log *slog.Logger, collector Collector, backfiller Backfiller) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Парсим JSON
 		var req models.CoinRequest
@@ -43,123 +59,90 @@ func New(log *slog.Logger, apiURL string, apiKey string, addNewCoin AddNewCoin)
 			render.JSON(w, r, map[string]string{"error": "Invalid request body"})
 			return
 		}
-
-		// Проверяем, существует ли валюта через внешний API
-		if !isValidCoin(apiURL, apiKey, req.Coin) {
-			log.Warn("Invalid coin", "coin", req.Coin)
+		req.Quote = models.NormalizeQuote(req.Quote)
+		if !models.ValidQuote(req.Quote) {
+			log.Warn("Invalid quote", "quote", req.Quote)
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": "Invalid quote: expected a currency or asset code of 2-16 letters and digits"})
+			return
+		}
+		if req.BackfillFrom != nil && (*req.BackfillFrom <= 0 || *req.BackfillFrom >= time.Now().UnixMilli()) {
+			log.Warn("Invalid backfill_from", "backfill_from", *req.BackfillFrom)
 			w.WriteHeader(http.StatusBadRequest)
-			render.JSON(w, r, map[string]string{"error": "Invalid coin"})
+			render.JSON(w, r, map[string]string{"error": backfill.ErrInvalidPeriod.Error()})
 			return
 		}
 
-		// Проверяем, не отслеживается ли уже эта криптовалюта
-		tracker.TrackedMutex.Lock()
-		if _, exists := tracker.TrackedCoins[req.Coin]; exists {
-			tracker.TrackedMutex.Unlock()
-			log.Warn("Coin is already being tracked", "coin", req.Coin)
+		resp := models.AddCoinResponse{Message: "Currency added to watchlist", State: models.CollectorRunning}
+		status := http.StatusOK
+
+		err := collector.Watch(r.Context(), req.Coin, req.Quote)
+		var providerErr *tracker.ProviderError
+		if req.AllowPending && errors.As(err, &providerErr) && !errors.Is(err, tracker.ErrInvalidCoin) &&
+			!errors.Is(err, tracker.ErrUnauthorized) {
+			log.Warn("Provider unavailable, coin is pending validation", "coin", req.Coin, "quote", req.Quote,
+				"kind", tracker.ErrorKind(err), "error", err)
+			err = collector.WatchPending(r.Context(), req.Coin, req.Quote)
+			resp = models.AddCoinResponse{Message: "Currency pending validation", State: models.CollectorPending}
+			status = http.StatusAccepted
+		}
+		switch {
+		case errors.Is(err, tracker.ErrInvalidCoin):
+			log.Warn("Unknown asset", "coin", req.Coin)
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": "Unknown asset"})
+			return
+		case errors.Is(err, tracker.ErrAlreadyTracked):
+			log.Warn("Coin is already being tracked", "coin", req.Coin, "quote", req.Quote)
 			render.JSON(w, r, map[string]string{"error": "Coin is already being tracked"})
 			return
+		case errors.Is(err, tracker.ErrUnauthorized):
+			log.Error("Provider rejected api key", "coin", req.Coin, "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Provider misconfigured"})
+			return
+		case errors.As(err, &providerErr):
+			log.Warn("Provider unavailable", "coin", req.Coin, "kind", tracker.ErrorKind(err), "error", err)
+			renderUnavailable(w, r, providerErr)
+			return
+		case err != nil:
+			log.Error("Failed to add currency", "coin", req.Coin, "quote", req.Quote, "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to add currency"})
+			return
 		}
 
-		// Добавляем криптовалюту в мапу отслеживаемых
-		tracker.TrackedCoins[req.Coin] = true
-		tracker.TrackedMutex.Unlock()
-
-		// Запускаем горутину для сбора данных
-		go func() {
-			startPriceCollector(r.Context(), log, addNewCoin, apiURL, apiKey, req.Coin)
-		}()
-
 		// Сообщаем, что валюта добавлена на наблюдение
-		render.JSON(w, r, map[string]string{"message": "Currency added to watchlist"})
-	}
-}
-
-func isValidCoin(apiURL, apiKey, coin string) bool {
-	url := fmt.Sprintf("%s/api/1/metadata?asset=%s&api_key=%s", apiURL, coin, apiKey)
-	resp, err := http.Get(url)
-	if err != nil {
-		return false
-	}
-	defer resp.Body.Close()
-
-	// Если статус ответа не 200, возвращаем false
-	return resp.StatusCode == http.StatusOK
-
-}
-
-func startPriceCollector(ctx context.Context, log *slog.Logger, addNewCoin AddNewCoin, apiURL, apiKey, coin string) {
-	// Создаем канал для остановки горутины
-	stopChan := make(chan struct{})
-
-	// Сохраняем канал в глобальной мапе
-	tracker.StopMutex.Lock()
-	tracker.StopChannels[coin] = stopChan
-	tracker.StopMutex.Unlock()
-
-	// Удаляем канал из мапы при завершении горутины
-	defer func() {
-		tracker.TrackedMutex.Lock()
-		delete(tracker.TrackedCoins, coin)
-		tracker.TrackedMutex.Unlock()
-	}()
-
-	ticker := time.NewTicker(time.Duration(N) * time.Second) // Интервал сбора данных
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-stopChan:
-			// Останавливаем горутину, если получен сигнал
-			log.Info("Stopped price collection for coin", "coin", coin)
-			return
-		case <-ticker.C:
-			info, err := fetchPriceFromAPI(apiURL, apiKey, coin)
+		if req.BackfillFrom != nil {
+			// Сбор цен уже запущен, поэтому ошибка создания задачи не отменяет добавление валюты
+			job, err := backfiller.Enqueue(r.Context(), req.Coin, req.Quote, *req.BackfillFrom)
 			if err != nil {
-				log.Warn("Failed to fetch price", "coin", coin, "error", err)
-				continue
-			}
-
-			// Сохраняем цену в базу данных
-			ctx = context.WithoutCancel(ctx)
-			if err := addNewCoin.AddCoin(ctx, info); err != nil {
-				log.Error("Failed to save price", "coin", coin, "error", err)
+				log.Error("Failed to create backfill job", "coin", req.Coin, "quote", req.Quote, "error", err)
+				resp.BackfillError = "Failed to create backfill job"
+			} else {
+				resp.JobID = &job.ID
 			}
 		}
+		w.WriteHeader(status)
+		render.JSON(w, r, resp)
 	}
 }
 
-func fetchPriceFromAPI(apiURL, apiKey, coin string) (models.Coin, error) {
-	currentTimeMillis := time.Now().Add(-24*time.Hour).Unix() * 1000
-
-	url := fmt.Sprintf("%s/api/1/market/history?asset=%s&from=%d&api_key=%s", apiURL, coin, currentTimeMillis,
-		apiKey)
-	resp, err := http.Get(url)
-	if err != nil || resp.StatusCode != http.StatusOK {
-		return models.Coin{}, fmt.Errorf("error: %s", err)
+// renderUnavailable отвечает 503, если провайдер ограничил частоту запросов, и 502 при остальных его ошибках,
+// с советом, через сколько секунд повторить запрос (заголовок Retry-After и поле retry_after)
+func renderUnavailable(w http.ResponseWriter, r *http.Request, err *tracker.ProviderError) {
+	retryAfter := DefaultRetryAfter
+	if err.RetryAfter > 0 {
+		retryAfter = err.RetryAfter
 	}
-	defer resp.Body.Close()
+	seconds := strconv.Itoa(int(retryAfter.Seconds()))
+	w.Header().Set("Retry-After", seconds)
 
-	var responseAPI struct {
-		Data struct {
-			Name         string       `json:"name"`
-			PriceHistory [][2]float64 `json:"price_history"`
-		} `json:"data"`
+	if errors.Is(err, tracker.ErrRateLimited) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		render.JSON(w, r, map[string]string{"error": "Provider rate limit exceeded", "retry_after": seconds})
+		return
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&responseAPI); err != nil {
-		return models.Coin{}, err
-	}
-
-	log.Printf("responseAPI getting %v", responseAPI.Data.PriceHistory[len(responseAPI.Data.PriceHistory)-1])
-
-	if price := responseAPI.Data.PriceHistory[0][1]; price != 0 {
-		coinInfo := models.Coin{
-			Name:      responseAPI.Data.Name,
-			Price:     responseAPI.Data.PriceHistory[len(responseAPI.Data.PriceHistory)-1][1],
-			Timestamp: int64(responseAPI.Data.PriceHistory[len(responseAPI.Data.PriceHistory)-1][0]),
-		}
-		return coinInfo, nil
-	}
-
-	return models.Coin{}, fmt.Errorf("price not found for %s", coin)
+	w.WriteHeader(http.StatusBadGateway)
+	render.JSON(w, r, map[string]string{"error": "Provider unavailable", "retry_after": seconds})
 }
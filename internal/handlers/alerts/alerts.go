@@ -0,0 +1,315 @@
+package alerts
+
+import (
+	"context"
+	rules "crypto_tracker/internal/alerts"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type AlertStorage interface {
+	CreateAlert(ctx context.Context, alert models.Alert) (int64, error)
+	GetAlert(ctx context.Context, id int64) (models.Alert, error)
+	GetAlerts(ctx context.Context) ([]models.Alert, error)
+	UpdateAlert(ctx context.Context, alert models.Alert, updatedAt int64) error
+	DeleteAlert(ctx context.Context, id int64) error
+	GetAlertEvents(ctx context.Context, id int64) ([]models.AlertEvent, error)
+}
+
+// @Summary Создать правило оповещения
+// @Description Создаёт правило: цена выше/ниже порога и (опционально) изменение цены в процентах за окно (window, например 1h).
+// @Description hysteresis_percent - на сколько процентов от порога цена должна вернуться, чтобы правило перешло в resolved.
+// @Description indicator (sma, ema, rsi, macd) и indicator_value - значение индикатора выше/ниже порога, например RSI выше 70.
+// @Description Индикатор считается по свечам длиной indicator_interval (по умолчанию 1h) с периодом indicator_period (по умолчанию 20, для rsi - 14; для macd - 12/26/9).
+// @Description cooldown - минимальная пауза между оповещениями (например 15m).
+// @ID create-alert
+// @Accept json
+// @Produce json
+// @Param request body models.AlertRequest true "Правило оповещения"
+// @Success 201 {object} models.Alert "Созданное правило"
+// @Failure 400 {object} map[string]string "error: Invalid request body"
+// @Failure 400 {object} map[string]string "error: Validation failed"
+// @Failure 500 {object} map[string]string "error: Failed to create alert"
+// @Router /alerts [post]
+func NewCreate(log *slog.Logger, alertStorage AlertStorage) http.HandlerFunc {
+	validate := validator.New()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		alert, ok := decodeAlert(log, validate, w, r)
+		if !ok {
+			return
+		}
+		alert.CreatedAt = time.Now().UnixMilli()
+
+		id, err := alertStorage.CreateAlert(r.Context(), alert)
+		if err != nil {
+			log.Error("Failed to create alert", "coin", alert.Coin, "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to create alert"})
+			return
+		}
+		alert.ID = id
+
+		w.WriteHeader(http.StatusCreated)
+		render.JSON(w, r, alert)
+	}
+}
+
+// @Summary Список правил оповещения
+// @Description Возвращает все правила оповещения.
+// @ID list-alerts
+// @Produce json
+// @Success 200 {array} models.Alert "Правила оповещения"
+// @Failure 500 {object} map[string]string "error: Failed to get alerts"
+// @Router /alerts [get]
+func NewList(log *slog.Logger, alertStorage AlertStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		alerts, err := alertStorage.GetAlerts(r.Context())
+		if err != nil {
+			log.Error("Failed to get alerts", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to get alerts"})
+			return
+		}
+		render.JSON(w, r, alerts)
+	}
+}
+
+// @Summary Получить правило оповещения
+// @Description Возвращает правило оповещения по идентификатору.
+// @ID get-alert
+// @Produce json
+// @Param id path int true "Идентификатор правила"
+// @Success 200 {object} models.Alert "Правило оповещения"
+// @Failure 400 {object} map[string]string "error: Invalid alert id"
+// @Failure 404 {object} map[string]string "error: Alert not found"
+// @Failure 500 {object} map[string]string "error: Failed to get alert"
+// @Router /alerts/{id} [get]
+func NewGet(log *slog.Logger, alertStorage AlertStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := parseID(log, w, r)
+		if !ok {
+			return
+		}
+
+		alert, err := alertStorage.GetAlert(r.Context(), id)
+		if err != nil {
+			renderStorageError(log, w, r, err, "Failed to get alert")
+			return
+		}
+		render.JSON(w, r, alert)
+	}
+}
+
+// @Summary Изменить правило оповещения
+// @Description Полностью заменяет условия правила и сбрасывает его состояние в ok и паузу между оповещениями (last_notified_at).
+// @Description Если правило было в firing или resolved, сброс записывается в историю переходов с price = 0.
+// @ID update-alert
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор правила"
+// @Param request body models.AlertRequest true "Правило оповещения"
+// @Success 200 {object} models.Alert "Изменённое правило"
+// @Failure 400 {object} map[string]string "error: Invalid request body"
+// @Failure 400 {object} map[string]string "error: Validation failed"
+// @Failure 404 {object} map[string]string "error: Alert not found"
+// @Failure 500 {object} map[string]string "error: Failed to update alert"
+// @Router /alerts/{id} [put]
+func NewUpdate(log *slog.Logger, alertStorage AlertStorage) http.HandlerFunc {
+	validate := validator.New()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := parseID(log, w, r)
+		if !ok {
+			return
+		}
+		alert, ok := decodeAlert(log, validate, w, r)
+		if !ok {
+			return
+		}
+		alert.ID = id
+
+		if err := alertStorage.UpdateAlert(r.Context(), alert, time.Now().UnixMilli()); err != nil {
+			renderStorageError(log, w, r, err, "Failed to update alert")
+			return
+		}
+
+		updated, err := alertStorage.GetAlert(r.Context(), id)
+		if err != nil {
+			renderStorageError(log, w, r, err, "Failed to get alert")
+			return
+		}
+		render.JSON(w, r, updated)
+	}
+}
+
+// @Summary Удалить правило оповещения
+// @Description Удаляет правило оповещения по идентификатору.
+// @ID delete-alert
+// @Produce json
+// @Param id path int true "Идентификатор правила"
+// @Success 200 {object} map[string]string "message: Alert deleted"
+// @Failure 400 {object} map[string]string "error: Invalid alert id"
+// @Failure 404 {object} map[string]string "error: Alert not found"
+// @Failure 500 {object} map[string]string "error: Failed to delete alert"
+// @Router /alerts/{id} [delete]
+func NewDelete(log *slog.Logger, alertStorage AlertStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := parseID(log, w, r)
+		if !ok {
+			return
+		}
+
+		if err := alertStorage.DeleteAlert(r.Context(), id); err != nil {
+			renderStorageError(log, w, r, err, "Failed to delete alert")
+			return
+		}
+		render.JSON(w, r, map[string]string{"message": "Alert deleted"})
+	}
+}
+
+// @Summary История состояний правила оповещения
+// @Description Возвращает переходы правила между состояниями (ok, firing, resolved) в хронологическом порядке.
+// @ID get-alert-events
+// @Produce json
+// @Param id path int true "Идентификатор правила"
+// @Success 200 {array} models.AlertEvent "Переходы состояний"
+// @Failure 400 {object} map[string]string "error: Invalid alert id"
+// @Failure 404 {object} map[string]string "error: Alert not found"
+// @Failure 500 {object} map[string]string "error: Failed to get alert events"
+// @Router /alerts/{id}/events [get]
+func NewEvents(log *slog.Logger, alertStorage AlertStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := parseID(log, w, r)
+		if !ok {
+			return
+		}
+
+		// Проверяем, что правило существует, чтобы отличить его отсутствие от пустой истории
+		if _, err := alertStorage.GetAlert(r.Context(), id); err != nil {
+			renderStorageError(log, w, r, err, "Failed to get alert events")
+			return
+		}
+
+		events, err := alertStorage.GetAlertEvents(r.Context(), id)
+		if err != nil {
+			renderStorageError(log, w, r, err, "Failed to get alert events")
+			return
+		}
+		render.JSON(w, r, events)
+	}
+}
+
+// decodeAlert парсит и валидирует тело запроса с правилом
+func decodeAlert(log *slog.Logger, validate *validator.Validate, w http.ResponseWriter, r *http.Request) (models.Alert, bool) {
+	var req models.AlertRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error("Failed to decode request body", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid request body"})
+		return models.Alert{}, false
+	}
+
+	if err := validate.Struct(req); err != nil {
+		log.Error("Validation failed", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Validation failed: coin and condition (above|below) are required, indicator must be one of sma, ema, rsi, macd"})
+		return models.Alert{}, false
+	}
+
+	// Правило должно содержать хотя бы одно условие
+	if req.Price == nil && req.ChangePercent == nil && req.Indicator == "" {
+		log.Error("Validation failed: empty alert rule")
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Validation failed: price, change_percent or indicator is required"})
+		return models.Alert{}, false
+	}
+
+	if req.Indicator != "" {
+		if req.IndicatorValue == nil {
+			log.Error("Validation failed: missing indicator value", "indicator", req.Indicator)
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": "Validation failed: indicator_value is required with indicator"})
+			return models.Alert{}, false
+		}
+		if req.IndicatorInterval != "" {
+			if interval, err := time.ParseDuration(req.IndicatorInterval); err != nil || interval < time.Millisecond {
+				log.Error("Validation failed: invalid indicator interval", "interval", req.IndicatorInterval, "error", err)
+				w.WriteHeader(http.StatusBadRequest)
+				render.JSON(w, r, map[string]string{"error": "Validation failed: invalid indicator_interval, must be at least 1ms (e.g. 1h)"})
+				return models.Alert{}, false
+			}
+		}
+	} else {
+		req.IndicatorValue, req.IndicatorPeriod, req.IndicatorInterval = nil, 0, ""
+	}
+
+	if req.ChangePercent != nil {
+		if window, err := time.ParseDuration(req.Window); err != nil || window <= 0 {
+			log.Error("Validation failed: invalid window", "window", req.Window, "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": "Validation failed: window is required with change_percent (e.g. 1h)"})
+			return models.Alert{}, false
+		}
+	} else {
+		req.Window = ""
+	}
+
+	if req.Cooldown != "" {
+		if cooldown, err := time.ParseDuration(req.Cooldown); err != nil || cooldown < 0 {
+			log.Error("Validation failed: invalid cooldown", "cooldown", req.Cooldown, "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": "Validation failed: invalid cooldown (e.g. 15m)"})
+			return models.Alert{}, false
+		}
+	}
+
+	return models.Alert{
+		Coin:              req.Coin,
+		Quote:             models.NormalizeQuote(req.Quote),
+		Condition:         req.Condition,
+		Price:             req.Price,
+		ChangePercent:     req.ChangePercent,
+		Window:            req.Window,
+		Indicator:         req.Indicator,
+		IndicatorValue:    req.IndicatorValue,
+		IndicatorPeriod:   req.IndicatorPeriod,
+		IndicatorInterval: req.IndicatorInterval,
+		HysteresisPercent: req.HysteresisPercent,
+		Cooldown:          req.Cooldown,
+		State:             rules.StateOK,
+	}, true
+}
+
+func parseID(log *slog.Logger, w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		log.Error("Invalid alert id", "id", chi.URLParam(r, "id"), "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid alert id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func renderStorageError(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error, message string) {
+	if errors.Is(err, storage.ErrAlertNotFound) {
+		log.Warn("Alert not found", "error", err)
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Alert not found"})
+		return
+	}
+	log.Error(message, "error", err)
+	w.WriteHeader(http.StatusInternalServerError)
+	render.JSON(w, r, map[string]string{"error": message})
+}
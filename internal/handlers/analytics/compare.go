@@ -0,0 +1,114 @@
+package analytics
+
+import (
+	"context"
+	calc "crypto_tracker/internal/analytics"
+	"crypto_tracker/internal/handlers/params"
+	"crypto_tracker/internal/models"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+var (
+	DefaultPeriod = 24 * time.Hour // Период сравнения, если since не указан
+	DefaultStep   = time.Hour      // Шаг сетки сравнения, если step не указан
+	MaxPoints     = 1000           // Максимальное число точек сетки сравнения
+)
+
+type SeriesStorage interface {
+	GetSeries(ctx context.Context, coin, quote string, from, to int64, resample models.Resample) ([]models.SeriesPoint, error)
+}
+
+// @Summary Сравнение динамики валют
+// @Description Возвращает цены валют в моменты since, since+step, ..., to, приведённые к 100 в момент since.
+// @Description Все валюты приводятся к сетке по одним правилам: цена в момент t агрегируется (agg) из цен интервала
+// @Description (t - step, t], пустые интервалы заполняются (fill, по умолчанию previous - forward-fill).
+// @Description Если у валюты нет значения на since, за 100 принимается её первое значение на сетке, а значения до него равны null.
+// @ID get-comparison
+// @Produce json
+// @Param coins query string true "Валюты через запятую (до 20)"
+// @Param since query int false "Начальный момент, timestamp в миллисекундах (по умолчанию to - 24h)"
+// @Param to query int false "Конечный момент, timestamp в миллисекундах (по умолчанию текущее время)"
+// @Param step query string false "Шаг сетки (по умолчанию 1h)"
+// @Param fill query string false "Заполнение пустых интервалов: none, previous, linear, zero (по умолчанию previous)"
+// @Param agg query string false "Агрегация цен в интервале: last, mean, first (по умолчанию last)"
+// @Param quote query string false "Котировка цен (по умолчанию USD)"
+// @Success 200 {object} models.Comparison "Сравнение"
+// @Failure 400 {object} map[string]string "error: Invalid query parameters"
+// @Failure 500 {object} map[string]string "error: Failed to get comparison"
+// @Router /v1/analytics/compare [get]
+func NewCompare(log *slog.Logger, seriesStorage SeriesStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		quote := models.NormalizeQuote(r.URL.Query().Get("quote"))
+
+		coins := unique(params.List(r, "coins"))
+		if len(coins) == 0 || len(coins) > MaxCoins {
+			badRequest(log, w, r, errors.New("coins must contain from 1 to 20 coins"))
+			return
+		}
+		to, err := params.Int64(r, "to", time.Now().UnixMilli())
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		since, err := params.Int64(r, "since", to-DefaultPeriod.Milliseconds())
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		resample, err := params.Resample(r, DefaultStep)
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		if since > to || (to-since)/resample.Step >= int64(MaxPoints) {
+			badRequest(log, w, r, errors.New("since must not exceed to and the period must contain at most 1000 steps"))
+			return
+		}
+
+		result := models.Comparison{
+			Quote:      quote,
+			Since:      since,
+			To:         to,
+			Step:       valueOr(r.URL.Query().Get("step"), time.Duration(resample.Step)*time.Millisecond),
+			Timestamps: make([]int64, 0),
+			Series:     make([]models.ComparisonSeries, 0, len(coins)),
+		}
+		for t := since; t <= to; t += resample.Step {
+			result.Timestamps = append(result.Timestamps, t)
+		}
+
+		for _, coin := range coins {
+			points, err := seriesStorage.GetSeries(r.Context(), coin, quote, since, to, resample)
+			if err != nil {
+				log.Error("Failed to get comparison", "error", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				render.JSON(w, r, map[string]string{"error": "Failed to get comparison"})
+				return
+			}
+
+			series := models.ComparisonSeries{Coin: coin, Values: make([]*float64, len(points))}
+			for i, point := range points {
+				if point.Value == nil || *point.Value <= 0 {
+					continue
+				}
+				if series.BasePrice == nil {
+					series.BasePrice = point.Value
+					series.BaseTimestamp = point.Timestamp
+				}
+				value := *point.Value / *series.BasePrice * 100
+				series.Values[i] = &value
+			}
+			if n := len(series.Values); n > 0 && series.Values[n-1] != nil {
+				change := calc.PercentChange(100, *series.Values[n-1])
+				series.Change = &change
+			}
+			result.Series = append(result.Series, series)
+		}
+		render.JSON(w, r, result)
+	}
+}
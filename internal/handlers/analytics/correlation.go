@@ -0,0 +1,167 @@
+package analytics
+
+import (
+	"context"
+	calc "crypto_tracker/internal/analytics"
+	"crypto_tracker/internal/handlers/params"
+	"crypto_tracker/internal/models"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+var (
+	DefaultWindow   = 30 * 24 * time.Hour // Окно, если window не указан
+	DefaultInterval = time.Hour           // Шаг сетки, если interval не указан
+	MaxCoins        = 20                  // Максимальное число валют в запросе
+	MaxIntervals    = 10000               // Максимальное число интервалов в окне
+)
+
+type CandleStorage interface {
+	GetCandles(ctx context.Context, coin, quote string, from, to, interval int64) ([]models.Candle, error)
+}
+
+// @Summary Матрица корреляций и беты
+// @Description Группирует цены валют за окно [to - window, to) в свечи длиной interval и считает логарифмические доходности
+// @Description между соседними свечами. Для каждой пары валют корреляция считается только по общим интервалам;
+// @Description их число возвращается в samples. Бета считается относительно benchmark (по умолчанию первая валюта).
+// @ID get-correlation
+// @Produce json
+// @Param coins query string true "Валюты через запятую (от 2 до 20)"
+// @Param window query string false "Окно (по умолчанию 30d)"
+// @Param interval query string false "Шаг сетки (по умолчанию 1h)"
+// @Param to query int false "Конец окна, timestamp в миллисекундах (по умолчанию текущее время)"
+// @Param benchmark query string false "Валюта для расчёта беты (по умолчанию первая из coins)"
+// @Param quote query string false "Котировка цен (по умолчанию USD)"
+// @Success 200 {object} models.CorrelationMatrix "Матрица корреляций"
+// @Failure 400 {object} map[string]string "error: Invalid query parameters"
+// @Failure 500 {object} map[string]string "error: Failed to get correlation"
+// @Router /v1/analytics/correlation [get]
+func NewCorrelation(log *slog.Logger, candleStorage CandleStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		quote := models.NormalizeQuote(query.Get("quote"))
+
+		coins := unique(params.List(r, "coins"))
+		if len(coins) < 2 || len(coins) > MaxCoins {
+			badRequest(log, w, r, errors.New("coins must contain from 2 to 20 coins"))
+			return
+		}
+		benchmark := query.Get("benchmark")
+		if benchmark == "" {
+			benchmark = coins[0]
+		}
+		window, err := params.Duration(r, "window", DefaultWindow)
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		interval, err := params.Duration(r, "interval", DefaultInterval)
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		to, err := params.Int64(r, "to", time.Now().UnixMilli())
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		if interval < time.Millisecond {
+			badRequest(log, w, r, errors.New("invalid interval: must be at least 1ms"))
+			return
+		}
+		step := interval.Milliseconds()
+		if window.Milliseconds()/step > int64(MaxIntervals) {
+			badRequest(log, w, r, errors.New("window must contain at most 10000 intervals"))
+			return
+		}
+		from := to - window.Milliseconds()
+
+		returns := make(map[string]map[int64]float64, len(coins)+1)
+		for _, coin := range append(slices.Clone(coins), benchmark) {
+			if _, ok := returns[coin]; ok {
+				continue
+			}
+			candles, err := candleStorage.GetCandles(r.Context(), coin, quote, from, to, step)
+			if err != nil {
+				log.Error("Failed to get correlation", "error", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				render.JSON(w, r, map[string]string{"error": "Failed to get correlation"})
+				return
+			}
+			timestamps, closes := make([]int64, len(candles)), make([]float64, len(candles))
+			for i, candle := range candles {
+				timestamps[i], closes[i] = candle.Timestamp, candle.Close
+			}
+			returns[coin] = calc.LogReturns(timestamps, closes, step)
+		}
+
+		result := models.CorrelationMatrix{
+			Coins:       coins,
+			Quote:       quote,
+			Window:      valueOr(query.Get("window"), window),
+			Interval:    valueOr(query.Get("interval"), interval),
+			From:        from,
+			To:          to,
+			Returns:     make([]int, len(coins)),
+			Correlation: make([][]*float64, len(coins)),
+			Samples:     make([][]int, len(coins)),
+			Benchmark:   benchmark,
+			Beta:        make([]*float64, len(coins)),
+			BetaSamples: make([]int, len(coins)),
+		}
+		for i, a := range coins {
+			result.Returns[i] = len(returns[a])
+			result.Correlation[i] = make([]*float64, len(coins))
+			result.Samples[i] = make([]int, len(coins))
+			for j, b := range coins {
+				x, y := calc.Overlap(returns[a], returns[b])
+				result.Samples[i][j] = len(x)
+				result.Correlation[i][j] = finite(calc.Correlation(x, y))
+			}
+
+			y, market := calc.Overlap(returns[a], returns[benchmark])
+			result.BetaSamples[i] = len(y)
+			result.Beta[i] = finite(calc.Beta(y, market))
+		}
+		render.JSON(w, r, result)
+	}
+}
+
+// unique убирает повторы, сохраняя порядок
+func unique(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if !slices.Contains(result, value) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// finite возвращает nil для NaN и бесконечностей, которые нельзя передать в JSON
+func finite(v float64) *float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return &v
+}
+
+// valueOr возвращает исходное значение параметра или, если он не задан, значение по умолчанию
+func valueOr(value string, def time.Duration) string {
+	if value != "" {
+		return value
+	}
+	return def.String()
+}
+
+func badRequest(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	log.Error("Invalid query parameters", "error", err)
+	w.WriteHeader(http.StatusBadRequest)
+	render.JSON(w, r, map[string]string{"error": "Invalid query parameters: " + err.Error()})
+}
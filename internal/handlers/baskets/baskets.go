@@ -0,0 +1,335 @@
+package baskets
+
+import (
+	"context"
+	"crypto_tracker/internal/handlers/params"
+	"crypto_tracker/internal/index"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"crypto_tracker/internal/tracker"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	DefaultPeriod = 24 * time.Hour // Период истории индекса, если from не указан
+	DefaultStep   = time.Hour      // Шаг истории индекса, если step не указан
+	MaxPoints     = 1000           // Максимальное число точек истории индекса
+)
+
+type BasketStorage interface {
+	CreateBasket(ctx context.Context, basket models.Basket) (int64, error)
+	GetBaskets(ctx context.Context) ([]models.Basket, error)
+	GetBasket(ctx context.Context, id int64) (models.Basket, error)
+	DeleteBasket(ctx context.Context, id int64) error
+}
+
+type Watcher interface {
+	Watch(ctx context.Context, coin, quote string) error
+}
+
+type IndexService interface {
+	History(ctx context.Context, basket models.Basket, from, to, step int64) ([]models.IndexPoint, error)
+}
+
+// @Summary Создать корзину
+// @Description Создаёт индекс из взвешенного набора валют. Веса нормируются так, чтобы их сумма была 1.
+// @Description Валюты корзины, которые ещё не отслеживаются, добавляются в список отслеживаемых в котировке корзины.
+// @Description Если провайдер недоступен, корзина не создаётся: возвращается 502 или 503 с видом ошибки kind.
+// @ID create-basket
+// @Accept json
+// @Produce json
+// @Param request body models.BasketRequest true "Корзина"
+// @Success 201 {object} models.Basket "Созданная корзина"
+// @Failure 400 {object} map[string]string "error: Invalid request body"
+// @Failure 400 {object} map[string]string "error: Invalid coin"
+// @Failure 500 {object} map[string]string "error: Failed to create basket"
+// @Failure 500 {object} map[string]string "error: Provider misconfigured"
+// @Failure 502 {object} map[string]string "error: Provider unavailable, coin: валюта, kind: вид ошибки провайдера"
+// @Failure 503 {object} map[string]string "error: Provider rate limit exceeded, coin: валюта, kind: rate_limited"
+// @Router /v1/baskets [post]
+func NewCreate(log *slog.Logger, basketStorage BasketStorage, watcher Watcher) http.HandlerFunc {
+	validate := validator.New()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.BasketRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || validate.Struct(req) != nil {
+			log.Error("Invalid request body", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{
+				"error": "Invalid request body: name, base_time and components with positive weights are required",
+			})
+			return
+		}
+
+		basket := models.Basket{
+			Name:       req.Name,
+			Quote:      models.NormalizeQuote(req.Quote),
+			BaseTime:   req.BaseTime,
+			Rebalance:  req.Rebalance,
+			Components: make([]models.BasketComponent, 0, len(req.Components)),
+			CreatedAt:  time.Now().UnixMilli(),
+		}
+		if !models.ValidQuote(basket.Quote) {
+			log.Error("Invalid quote", "quote", basket.Quote)
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": "Invalid quote: expected a currency or asset code of 2-16 letters and digits"})
+			return
+		}
+		if basket.Rebalance == "" {
+			basket.Rebalance = index.RebalanceNone
+		}
+
+		var total float64
+		seen := make(map[string]bool, len(req.Components))
+		for _, component := range req.Components {
+			component.Coin = strings.TrimSpace(component.Coin)
+			if seen[component.Coin] {
+				log.Error("Duplicate basket component", "coin", component.Coin)
+				w.WriteHeader(http.StatusBadRequest)
+				render.JSON(w, r, map[string]string{"error": "Invalid request body: duplicate coin " + component.Coin})
+				return
+			}
+			seen[component.Coin] = true
+			total += component.Weight
+			basket.Components = append(basket.Components, component)
+		}
+		for i := range basket.Components {
+			basket.Components[i].Weight /= total
+		}
+
+		for _, component := range basket.Components {
+			err := watcher.Watch(r.Context(), component.Coin, basket.Quote)
+			if errors.Is(err, tracker.ErrInvalidCoin) {
+				log.Warn("Invalid coin", "coin", component.Coin)
+				w.WriteHeader(http.StatusBadRequest)
+				render.JSON(w, r, map[string]string{"error": "Invalid coin: " + component.Coin})
+				return
+			}
+			if err == nil {
+				log.Info("Currency added to watchlist from basket", "coin", component.Coin, "quote", basket.Quote)
+			}
+			if err != nil && !errors.Is(err, tracker.ErrAlreadyTracked) {
+				renderWatchError(log, w, r, component.Coin, err)
+				return
+			}
+		}
+
+		id, err := basketStorage.CreateBasket(r.Context(), basket)
+		if err != nil {
+			log.Error("Failed to create basket", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to create basket"})
+			return
+		}
+		basket.ID = id
+
+		w.WriteHeader(http.StatusCreated)
+		render.JSON(w, r, basket)
+	}
+}
+
+// @Summary Список корзин
+// @Description Возвращает все корзины с составом.
+// @ID list-baskets
+// @Produce json
+// @Success 200 {array} models.Basket "Корзины"
+// @Failure 500 {object} map[string]string "error: Failed to get baskets"
+// @Router /v1/baskets [get]
+func NewList(log *slog.Logger, basketStorage BasketStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		baskets, err := basketStorage.GetBaskets(r.Context())
+		if err != nil {
+			log.Error("Failed to get baskets", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to get baskets"})
+			return
+		}
+		render.JSON(w, r, baskets)
+	}
+}
+
+// @Summary Получить корзину
+// @Description Возвращает корзину с составом.
+// @ID get-basket
+// @Produce json
+// @Param id path int true "Идентификатор корзины"
+// @Success 200 {object} models.Basket "Корзина"
+// @Failure 400 {object} map[string]string "error: Invalid basket id"
+// @Failure 404 {object} map[string]string "error: Basket not found"
+// @Failure 500 {object} map[string]string "error: Failed to get basket"
+// @Router /v1/baskets/{id} [get]
+func NewGet(log *slog.Logger, basketStorage BasketStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := parseID(log, w, r)
+		if !ok {
+			return
+		}
+
+		basket, err := basketStorage.GetBasket(r.Context(), id)
+		if err != nil {
+			renderError(log, w, r, err, "Failed to get basket")
+			return
+		}
+		render.JSON(w, r, basket)
+	}
+}
+
+// @Summary Удалить корзину
+// @Description Удаляет корзину. Сбор цен её валют не останавливается.
+// @ID delete-basket
+// @Produce json
+// @Param id path int true "Идентификатор корзины"
+// @Success 200 {object} map[string]string "message: Basket deleted"
+// @Failure 400 {object} map[string]string "error: Invalid basket id"
+// @Failure 404 {object} map[string]string "error: Basket not found"
+// @Failure 500 {object} map[string]string "error: Failed to delete basket"
+// @Router /v1/baskets/{id} [delete]
+func NewDelete(log *slog.Logger, basketStorage BasketStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := parseID(log, w, r)
+		if !ok {
+			return
+		}
+
+		if err := basketStorage.DeleteBasket(r.Context(), id); err != nil {
+			renderError(log, w, r, err, "Failed to delete basket")
+			return
+		}
+		render.JSON(w, r, map[string]string{"message": "Basket deleted"})
+	}
+}
+
+// @Summary История индекса корзины
+// @Description Возвращает уровень индекса в моменты from, from+step, ..., to (не более 1000 точек, не раньше base_time).
+// @Description В base_time индекс равен 100; цены берутся последние не позже каждого момента.
+// @ID get-basket-history
+// @Produce json
+// @Param id path int true "Идентификатор корзины"
+// @Param from query int false "Начало периода, timestamp в миллисекундах (по умолчанию to - 24h)"
+// @Param to query int false "Конец периода, timestamp в миллисекундах (по умолчанию текущее время)"
+// @Param step query string false "Шаг (по умолчанию 1h)"
+// @Success 200 {array} models.IndexPoint "Уровень индекса по времени"
+// @Failure 400 {object} map[string]string "error: Invalid query parameters"
+// @Failure 404 {object} map[string]string "error: Basket not found"
+// @Failure 404 {object} map[string]string "error: Price not found"
+// @Failure 500 {object} map[string]string "error: Failed to get basket history"
+// @Router /v1/baskets/{id}/history [get]
+func NewHistory(log *slog.Logger, basketStorage BasketStorage, indexService IndexService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := parseID(log, w, r)
+		if !ok {
+			return
+		}
+
+		to, err := params.Int64(r, "to", time.Now().UnixMilli())
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		from, err := params.Int64(r, "from", to-DefaultPeriod.Milliseconds())
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		step, err := params.Duration(r, "step", DefaultStep)
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		if step < time.Millisecond {
+			badRequest(log, w, r, errors.New("invalid step: must be at least 1ms"))
+			return
+		}
+		if from > to || (to-from)/step.Milliseconds() >= int64(MaxPoints) {
+			badRequest(log, w, r, errors.New("from must not exceed to and the period must contain at most 1000 steps"))
+			return
+		}
+
+		basket, err := basketStorage.GetBasket(r.Context(), id)
+		if err != nil {
+			renderError(log, w, r, err, "Failed to get basket history")
+			return
+		}
+
+		points, err := indexService.History(r.Context(), basket, from, to, step.Milliseconds())
+		if err != nil {
+			renderError(log, w, r, err, "Failed to get basket history")
+			return
+		}
+		render.JSON(w, r, points)
+	}
+}
+
+func parseID(log *slog.Logger, w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		log.Error("Invalid basket id", "id", chi.URLParam(r, "id"), "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid basket id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func badRequest(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	log.Error("Invalid query parameters", "error", err)
+	w.WriteHeader(http.StatusBadRequest)
+	render.JSON(w, r, map[string]string{"error": "Invalid query parameters: " + err.Error()})
+}
+
+func renderError(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.Is(err, storage.ErrBasketNotFound):
+		log.Warn("Basket not found", "error", err)
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Basket not found"})
+	case errors.Is(err, storage.ErrPriceNotFound):
+		log.Warn("Price not found", "error", err)
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Price not found: no price for a basket component at base_time"})
+	case errors.Is(err, index.ErrTooManySamples):
+		badRequest(log, w, r, errors.New("too many rebalancing points between base_time and to"))
+	default:
+		log.Error(message, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": message})
+	}
+}
+
+// renderWatchError отвечает на ошибку добавления валюты корзины в список отслеживаемых: 503, если провайдер
+// ограничил частоту запросов, 502 при остальных его ошибках и 500, если провайдер отклонил ключ API или ошибка внутренняя
+func renderWatchError(log *slog.Logger, w http.ResponseWriter, r *http.Request, coin string, err error) {
+	kind := tracker.ErrorKind(err)
+	log.Warn("Failed to add currency to watchlist", "coin", coin, "kind", kind, "error", err)
+
+	var providerErr *tracker.ProviderError
+	switch {
+	case errors.Is(err, tracker.ErrUnauthorized):
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Provider misconfigured", "coin": coin, "kind": kind})
+	case errors.As(err, &providerErr):
+		if providerErr.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(providerErr.RetryAfter.Seconds())))
+		}
+		if errors.Is(err, tracker.ErrRateLimited) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			render.JSON(w, r, map[string]string{"error": "Provider rate limit exceeded", "coin": coin, "kind": kind})
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+		render.JSON(w, r, map[string]string{"error": "Provider unavailable", "coin": coin, "kind": kind})
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to add currency to watchlist", "coin": coin, "kind": kind})
+	}
+}
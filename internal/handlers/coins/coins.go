@@ -0,0 +1,92 @@
+package coins
+
+import (
+	"context"
+	"crypto_tracker/internal/analytics"
+	"crypto_tracker/internal/handlers/params"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+var DefaultWindow = 24 * time.Hour // Окно статистики, если window не указан
+
+type StatsStorage interface {
+	GetPriceStats(ctx context.Context, coin, quote string, from, to int64) (models.CoinStats, error)
+}
+
+// @Summary Статистика цены за окно
+// @Description Считает по сохранённым ценам за окно (to - window, to] изменение, минимум, максимум, среднее,
+// @Description стандартное отклонение и годовую волатильность логарифмических доходностей.
+// @ID get-coin-stats
+// @Produce json
+// @Param coin path string true "Валюта (например Bitcoin)"
+// @Param window query string false "Окно: 24h, 7d, 30d и т.п. (по умолчанию 24h)"
+// @Param to query int false "Конец окна, timestamp в миллисекундах (по умолчанию текущее время)"
+// @Param quote query string false "Котировка цен (по умолчанию USD)"
+// @Success 200 {object} models.CoinStats "Статистика"
+// @Failure 400 {object} map[string]string "error: Invalid query parameters"
+// @Failure 404 {object} map[string]string "error: No prices in window"
+// @Failure 500 {object} map[string]string "error: Failed to get stats"
+// @Router /v1/coins/{coin}/stats [get]
+func NewStats(log *slog.Logger, statsStorage StatsStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		coin := chi.URLParam(r, "coin")
+		quote := models.NormalizeQuote(r.URL.Query().Get("quote"))
+
+		window, err := params.Duration(r, "window", DefaultWindow)
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		to, err := params.Int64(r, "to", time.Now().UnixMilli())
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+
+		stats, err := statsStorage.GetPriceStats(r.Context(), coin, quote, to-window.Milliseconds(), to)
+		if errors.Is(err, storage.ErrPriceNotFound) {
+			log.Warn("No prices in window", "coin", coin, "quote", quote, "window", window)
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, map[string]string{"error": "No prices in window"})
+			return
+		}
+		if err != nil {
+			log.Error("Failed to get stats", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to get stats"})
+			return
+		}
+
+		stats.Window = window.String()
+		if value := r.URL.Query().Get("window"); value != "" {
+			stats.Window = value
+		}
+		stats.Change = stats.Last - stats.First
+		stats.ChangePercent = analytics.PercentChange(stats.First, stats.Last)
+		if stats.Samples > 1 {
+			interval := time.Duration((stats.LastTimestamp-stats.FirstTimestamp)/(stats.Samples-1)) * time.Millisecond
+			stats.Volatility = analytics.AnnualisedVolatility(stats.ReturnsStdDev, interval)
+		}
+		render.JSON(w, r, stats)
+	}
+}
+
+func renderFXRateNotFound(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	log.Warn("FX rate not found", "error", err)
+	w.WriteHeader(http.StatusNotFound)
+	render.JSON(w, r, map[string]string{"error": "FX rate not found"})
+}
+
+func badRequest(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	log.Error("Invalid query parameters", "error", err)
+	w.WriteHeader(http.StatusBadRequest)
+	render.JSON(w, r, map[string]string{"error": "Invalid query parameters: " + err.Error()})
+}
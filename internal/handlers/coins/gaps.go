@@ -0,0 +1,86 @@
+package coins
+
+import (
+	"context"
+	"crypto_tracker/internal/handlers/params"
+	"crypto_tracker/internal/models"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+var (
+	DefaultGapWindow = 24 * time.Hour      // Окно поиска пропусков, если window не указан
+	MaxGapWindow     = 90 * 24 * time.Hour // Максимальное окно поиска пропусков
+)
+
+type GapStorage interface {
+	GetGaps(ctx context.Context, coin, quote string, from, to, threshold int64) ([]models.Gap, error)
+}
+
+type GapScanner interface {
+	Threshold() time.Duration
+	LastScan(coin, quote string) (models.GapScan, bool)
+}
+
+// @Summary Пропуски в ценах
+// @Description Возвращает промежутки между соседними сохранёнными ценами за окно, длиннее порога
+// @Description (по умолчанию - порог фоновой задачи дозаполнения), и результат последней проверки этой задачей.
+// @ID get-coin-gaps
+// @Produce json
+// @Param coin path string true "Валюта (например Bitcoin)"
+// @Param window query string false "Окно (по умолчанию 24h, не больше 90d)"
+// @Param threshold query string false "Минимальная длина пропуска (по умолчанию GAP_FACTOR интервалов сбора)"
+// @Param quote query string false "Котировка цен (по умолчанию USD)"
+// @Success 200 {object} models.GapReport "Пропуски"
+// @Failure 400 {object} map[string]string "error: Invalid query parameters"
+// @Failure 500 {object} map[string]string "error: Failed to get gaps"
+// @Router /v1/coins/{coin}/gaps [get]
+func NewGaps(log *slog.Logger, gapStorage GapStorage, scanner GapScanner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		coin := chi.URLParam(r, "coin")
+		quote := models.NormalizeQuote(r.URL.Query().Get("quote"))
+
+		window, err := params.Duration(r, "window", DefaultGapWindow)
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		if window > MaxGapWindow {
+			badRequest(log, w, r, errors.New("window must not exceed 90d"))
+			return
+		}
+		threshold, err := params.Duration(r, "threshold", scanner.Threshold())
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+
+		to := time.Now().UnixMilli()
+		from := to - window.Milliseconds()
+		gaps, err := gapStorage.GetGaps(r.Context(), coin, quote, from, to, threshold.Milliseconds())
+		if err != nil {
+			log.Error("Failed to get gaps", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to get gaps"})
+			return
+		}
+
+		report := models.GapReport{
+			Coin:      coin,
+			Quote:     quote,
+			From:      from,
+			To:        to,
+			Threshold: threshold.String(),
+			Gaps:      gaps,
+		}
+		if scan, ok := scanner.LastScan(coin, quote); ok {
+			report.LastScan = &scan
+		}
+		render.JSON(w, r, report)
+	}
+}
@@ -0,0 +1,105 @@
+package coins
+
+import (
+	"context"
+	"crypto_tracker/internal/handlers/params"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+var MaxHistoryPoints = 1000 // Максимальное число точек истории цены
+
+type SeriesStorage interface {
+	GetSeries(ctx context.Context, coin, quote string, from, to int64, resample models.Resample) ([]models.SeriesPoint, error)
+	GetConvertedSeries(ctx context.Context, coin, quote, convert string, from, to int64, resample models.Resample) ([]models.SeriesPoint, error)
+}
+
+// @Summary История цены на регулярной сетке
+// @Description Возвращает цену валюты в моменты from, from+step, ..., to (не более 1000 точек).
+// @Description Цена в момент t агрегируется (agg) из цен интервала (t - step, t], пустые интервалы заполняются (fill);
+// @Description заполненные точки помечаются filled.
+// @Description Если указан convert (например EUR), значение каждой точки пересчитывается по курсу фиатной валюты, действовавшему в её момент.
+// @ID get-coin-history
+// @Produce json
+// @Param coin path string true "Валюта (например Bitcoin)"
+// @Param from query int false "Начало периода, timestamp в миллисекундах (по умолчанию to - 24h)"
+// @Param to query int false "Конец периода, timestamp в миллисекундах (по умолчанию текущее время)"
+// @Param step query string false "Шаг сетки (по умолчанию 1h)"
+// @Param fill query string false "Заполнение пустых интервалов: none, previous, linear, zero (по умолчанию previous)"
+// @Param agg query string false "Агрегация цен в интервале: last, mean, first (по умолчанию last)"
+// @Param quote query string false "Котировка цен (по умолчанию USD)"
+// @Param convert query string false "Фиатная валюта для пересчёта (например EUR)"
+// @Success 200 {object} models.PriceHistory "История цены"
+// @Failure 400 {object} map[string]string "error: Invalid query parameters"
+// @Failure 404 {object} map[string]string "error: FX rate not found"
+// @Failure 500 {object} map[string]string "error: Failed to get history"
+// @Router /v1/coins/{coin}/history [get]
+func NewHistory(log *slog.Logger, seriesStorage SeriesStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		coin := chi.URLParam(r, "coin")
+		quote := models.NormalizeQuote(r.URL.Query().Get("quote"))
+		convert := quote
+		if value := r.URL.Query().Get("convert"); value != "" {
+			convert = models.NormalizeQuote(value)
+		}
+
+		to, err := params.Int64(r, "to", time.Now().UnixMilli())
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		from, err := params.Int64(r, "from", to-DefaultPeriod.Milliseconds())
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		resample, err := params.Resample(r, DefaultInterval)
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		if from > to || (to-from)/resample.Step >= int64(MaxHistoryPoints) {
+			badRequest(log, w, r, errors.New("from must not exceed to and the period must contain at most 1000 steps"))
+			return
+		}
+
+		var points []models.SeriesPoint
+		if convert != quote {
+			points, err = seriesStorage.GetConvertedSeries(r.Context(), coin, quote, convert, from, to, resample)
+		} else {
+			points, err = seriesStorage.GetSeries(r.Context(), coin, quote, from, to, resample)
+		}
+		if errors.Is(err, storage.ErrFXRateNotFound) {
+			renderFXRateNotFound(log, w, r, err)
+			return
+		}
+		if err != nil {
+			log.Error("Failed to get history", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to get history"})
+			return
+		}
+
+		history := models.PriceHistory{
+			Coin:   coin,
+			Quote:  convert,
+			From:   from,
+			To:     to,
+			Step:   (time.Duration(resample.Step) * time.Millisecond).String(),
+			Fill:   resample.Fill,
+			Agg:    resample.Agg,
+			Points: points,
+		}
+		if value := r.URL.Query().Get("step"); value != "" {
+			history.Step = value
+		}
+		render.JSON(w, r, history)
+	}
+}
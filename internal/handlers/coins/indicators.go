@@ -0,0 +1,179 @@
+package coins
+
+import (
+	"context"
+	"crypto_tracker/internal/analytics"
+	"crypto_tracker/internal/handlers/params"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+var (
+	DefaultInterval = time.Hour      // Длина свечи, если interval не указан
+	DefaultPeriod   = 24 * time.Hour // Период индикатора, если from не указан
+	MaxCandles      = 1000           // Максимальное число свечей в ответе
+	WarmupFactor    = 3              // Во сколько раз больше lookback свечей загружается до from для разгона индикатора
+)
+
+type CandleStorage interface {
+	GetCandles(ctx context.Context, coin, quote string, from, to, interval int64) ([]models.Candle, error)
+	GetConvertedCandles(ctx context.Context, coin, quote, convert string, from, to, interval int64) ([]models.Candle, error)
+}
+
+// @Summary Технический индикатор
+// @Description Вычисляет индикатор по ценам закрытия свечей длиной interval, собранных из сохранённых цен за [from, to).
+// @Description Для разгона индикатора дополнительно загружаются свечи до from; точки, где индикатор ещё не определён, не возвращаются.
+// @Description bollinger возвращает middle, upper, lower; macd - macd, signal, histogram.
+// @Description Если указан convert (например EUR), свечи пересчитываются по курсу фиатной валюты, действовавшему в начале каждой свечи.
+// @ID get-coin-indicators
+// @Produce json
+// @Param coin path string true "Валюта (например Bitcoin)"
+// @Param type query string true "Индикатор: sma, ema, rsi, bollinger, macd"
+// @Param period query int false "Период (по умолчанию 20, для rsi - 14)"
+// @Param fast query int false "Быстрый период macd (по умолчанию 12)"
+// @Param slow query int false "Медленный период macd (по умолчанию 26)"
+// @Param signal query int false "Период сигнальной линии macd (по умолчанию 9)"
+// @Param k query number false "Ширина полос bollinger в стандартных отклонениях (по умолчанию 2)"
+// @Param interval query string false "Длина свечи (по умолчанию 1h)"
+// @Param from query int false "Начало периода, timestamp в миллисекундах (по умолчанию to - 24h)"
+// @Param to query int false "Конец периода, timestamp в миллисекундах (по умолчанию текущее время)"
+// @Param quote query string false "Котировка цен (по умолчанию USD)"
+// @Param convert query string false "Фиатная валюта для пересчёта (например EUR)"
+// @Success 200 {object} models.IndicatorSeries "Значения индикатора"
+// @Failure 400 {object} map[string]string "error: Invalid query parameters"
+// @Failure 404 {object} map[string]string "error: FX rate not found"
+// @Failure 500 {object} map[string]string "error: Failed to get indicator"
+// @Router /v1/coins/{coin}/indicators [get]
+func NewIndicators(log *slog.Logger, candleStorage CandleStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		coin := chi.URLParam(r, "coin")
+		query := r.URL.Query()
+		quote := models.NormalizeQuote(query.Get("quote"))
+		convert := quote
+		if value := query.Get("convert"); value != "" {
+			convert = models.NormalizeQuote(value)
+		}
+		kind := query.Get("type")
+
+		indicatorParams, err := parseIndicatorParams(r)
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		interval, err := params.Duration(r, "interval", DefaultInterval)
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		to, err := params.Int64(r, "to", time.Now().UnixMilli())
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		from, err := params.Int64(r, "from", to-DefaultPeriod.Milliseconds())
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		if interval < time.Millisecond {
+			badRequest(log, w, r, errors.New("invalid interval: must be at least 1ms"))
+			return
+		}
+		step := interval.Milliseconds()
+		if from > to || (to-from)/step >= int64(MaxCandles) {
+			badRequest(log, w, r, errors.New("from must not exceed to and the period must contain at most 1000 intervals"))
+			return
+		}
+		// Проверяем тип индикатора до обращения к хранилищу
+		if _, err := analytics.Indicator(kind, nil, indicatorParams); err != nil {
+			badRequest(log, w, r, errors.New("type must be one of sma, ema, rsi, bollinger, macd"))
+			return
+		}
+
+		lookback := indicatorParams.WithDefaults(kind).Lookback(kind)
+		warmup := int64(WarmupFactor*lookback) * step
+		var candles []models.Candle
+		if convert != quote {
+			candles, err = candleStorage.GetConvertedCandles(r.Context(), coin, quote, convert, from/step*step-warmup, to, step)
+		} else {
+			candles, err = candleStorage.GetCandles(r.Context(), coin, quote, from/step*step-warmup, to, step)
+		}
+		if errors.Is(err, storage.ErrFXRateNotFound) {
+			renderFXRateNotFound(log, w, r, err)
+			return
+		}
+		if err != nil {
+			log.Error("Failed to get indicator", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to get indicator"})
+			return
+		}
+
+		closes := make([]float64, len(candles))
+		for i, candle := range candles {
+			closes[i] = candle.Close
+		}
+		series, _ := analytics.Indicator(kind, closes, indicatorParams)
+
+		result := models.IndicatorSeries{
+			Coin:     coin,
+			Quote:    convert,
+			Type:     kind,
+			Interval: interval.String(),
+			Points:   make([]models.IndicatorPoint, 0, len(candles)),
+		}
+		if value := query.Get("interval"); value != "" {
+			result.Interval = value
+		}
+	points:
+		for i, candle := range candles {
+			if candle.Timestamp < from/step*step {
+				continue
+			}
+			point := models.IndicatorPoint{
+				Timestamp: candle.Timestamp,
+				Close:     candle.Close,
+				Values:    make(map[string]float64, len(series)),
+			}
+			for name, values := range series {
+				if math.IsNaN(values[i]) {
+					continue points
+				}
+				point.Values[name] = values[i]
+			}
+			result.Points = append(result.Points, point)
+		}
+		render.JSON(w, r, result)
+	}
+}
+
+func parseIndicatorParams(r *http.Request) (analytics.IndicatorParams, error) {
+	var p analytics.IndicatorParams
+	for name, target := range map[string]*int{"period": &p.Period, "fast": &p.Fast, "slow": &p.Slow, "signal": &p.Signal} {
+		value, err := params.Int64(r, name, 0)
+		if err != nil {
+			return p, err
+		}
+		if value < 0 || value > int64(MaxCandles) {
+			return p, errors.New("invalid " + name + ": must be positive and at most 1000")
+		}
+		*target = int(value)
+	}
+	if value := r.URL.Query().Get("k"); value != "" {
+		k, err := strconv.ParseFloat(value, 64)
+		if err != nil || k <= 0 {
+			return p, errors.New("invalid k: must be a positive number")
+		}
+		p.K = k
+	}
+	return p, nil
+}
@@ -0,0 +1,113 @@
+package coins
+
+import (
+	"context"
+	"crypto_tracker/internal/handlers/params"
+	"crypto_tracker/internal/models"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+var (
+	DefaultQualityWindow = 7 * 24 * time.Hour  // Окно отчёта о качестве, если window не указан
+	MaxQualityWindow     = 90 * 24 * time.Hour // Максимальное окно отчёта о качестве
+	DefaultLargestGaps   = 5                   // Число самых длинных пропусков, если gaps не указан
+	MaxLargestGaps       = 100
+)
+
+type QualityStorage interface {
+	GetSampleStats(ctx context.Context, coin, quote string, from, to int64) (models.SampleStats, error)
+	GetLargestGaps(ctx context.Context, coin, quote string, from, to int64, limit int) ([]models.Gap, error)
+}
+
+// @Summary Качество ряда цен
+// @Description Сравнивает число сохранённых цен за окно [to - window, to] с ожидаемым при сборе раз в интервал коллектора,
+// @Description возвращает покрытие (различные моменты времени от ожидаемого числа), самые длинные промежутки между ценами,
+// @Description число цен с повторяющимся временем и цен, отклонённых проверкой перед сохранением.
+// @ID get-coin-quality
+// @Produce json
+// @Param coin path string true "Валюта (например Bitcoin)"
+// @Param window query string false "Окно (по умолчанию 7d, не больше 90d)"
+// @Param to query int false "Конец окна, timestamp в миллисекундах (по умолчанию текущее время)"
+// @Param gaps query int false "Число самых длинных пропусков (по умолчанию 5, не больше 100)"
+// @Param quote query string false "Котировка цен (по умолчанию USD)"
+// @Success 200 {object} models.DataQuality "Отчёт о качестве"
+// @Failure 400 {object} map[string]string "error: Invalid query parameters"
+// @Failure 500 {object} map[string]string "error: Failed to get quality"
+// @Router /v1/coins/{coin}/quality [get]
+func NewQuality(log *slog.Logger, qualityStorage QualityStorage, interval time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		coin := chi.URLParam(r, "coin")
+		query := r.URL.Query()
+		quote := models.NormalizeQuote(query.Get("quote"))
+
+		window, err := params.Duration(r, "window", DefaultQualityWindow)
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		if window < interval || window > MaxQualityWindow {
+			badRequest(log, w, r, errors.New("window must be between the collection interval and 90d"))
+			return
+		}
+		to, err := params.Int64(r, "to", time.Now().UnixMilli())
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		limit, err := params.Int64(r, "gaps", int64(DefaultLargestGaps))
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		if limit <= 0 || limit > int64(MaxLargestGaps) {
+			badRequest(log, w, r, errors.New("gaps must be between 1 and 100"))
+			return
+		}
+		from := to - window.Milliseconds()
+
+		stats, err := qualityStorage.GetSampleStats(r.Context(), coin, quote, from, to)
+		if err != nil {
+			renderQualityError(log, w, r, err)
+			return
+		}
+		gaps, err := qualityStorage.GetLargestGaps(r.Context(), coin, quote, from, to, int(limit))
+		if err != nil {
+			renderQualityError(log, w, r, err)
+			return
+		}
+
+		expected := window.Milliseconds() / interval.Milliseconds()
+		report := models.DataQuality{
+			Coin:                coin,
+			Quote:               quote,
+			Window:              window.String(),
+			From:                from,
+			To:                  to,
+			Interval:            interval.String(),
+			Expected:            expected,
+			Actual:              stats.Samples,
+			Coverage:            min(float64(stats.Distinct)/float64(expected)*100, 100),
+			Duplicates:          stats.Duplicates,
+			Quarantined:         stats.Quarantined,
+			LargestGaps:         gaps,
+			MedianIngestLatency: stats.Latency,
+			Providers:           stats.Providers,
+		}
+		if value := query.Get("window"); value != "" {
+			report.Window = value
+		}
+		render.JSON(w, r, report)
+	}
+}
+
+func renderQualityError(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	log.Error("Failed to get quality", "error", err)
+	w.WriteHeader(http.StatusInternalServerError)
+	render.JSON(w, r, map[string]string{"error": "Failed to get quality"})
+}
@@ -0,0 +1,30 @@
+package collectors
+
+import (
+	"crypto_tracker/internal/models"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/render"
+)
+
+type StatusProvider interface {
+	Statuses() []models.CollectorStatus
+}
+
+// @Summary Состояние сбора цен
+// @Description Возвращает состояние горутин сбора цен отслеживаемых пар (pending_validation, running, restarting, failed),
+// @Description число паник, время последней сохранённой цены,
+// @Description последнюю ошибку, её вид (not_found, rate_limited, unauthorized, upstream, malformed, internal)
+// @Description и число ошибок подряд.
+// @ID list-collectors
+// @Produce json
+// @Success 200 {array} models.CollectorStatus "Состояние сбора"
+// @Router /v1/collectors [get]
+func NewList(log *slog.Logger, provider StatusProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		statuses := provider.Statuses()
+		log.Debug("Collector statuses", "count", len(statuses))
+		render.JSON(w, r, statuses)
+	}
+}
@@ -0,0 +1,82 @@
+package convert
+
+import (
+	"context"
+	"crypto_tracker/internal/handlers/params"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+type Converter interface {
+	Convert(ctx context.Context, amount float64, from, to string, at int64) (models.Conversion, error)
+}
+
+// @Summary Конвертировать сумму на момент времени
+// @Description Пересчитывает amount из from в to по ценам и курсам, действовавшим в момент at.
+// @Description from и to - название криптовалюты (Bitcoin), её тикер (BTC) или код валюты (USD, EUR).
+// @Description Тикер распознаётся, когда коллектор уже получил хотя бы одну цену этой валюты.
+// @Description Фиатными считаются USD и валюты из FX_CURRENCIES: они пересчитываются по курсам fx_rates.
+// @Description В legs возвращаются все использованные цены и курсы с точным временем выборки.
+// @ID convert
+// @Produce json
+// @Param amount query number true "Сумма (положительное число)"
+// @Param from query string true "Из чего конвертировать (например Bitcoin)"
+// @Param to query string true "Во что конвертировать (например Ethereum или EUR)"
+// @Param at query int false "Timestamp в миллисекундах (по умолчанию текущее время)"
+// @Success 200 {object} models.Conversion "Результат конвертации"
+// @Failure 400 {object} map[string]string "error: Invalid query parameters"
+// @Failure 404 {object} map[string]string "error: Price or fx rate not found"
+// @Failure 500 {object} map[string]string "error: Failed to convert"
+// @Router /v1/convert [get]
+func New(log *slog.Logger, converter Converter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		from, to := strings.TrimSpace(query.Get("from")), strings.TrimSpace(query.Get("to"))
+		if from == "" || to == "" {
+			log.Error("Validation failed: from and to are required")
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": "Validation failed: amount, from and to are required"})
+			return
+		}
+
+		amount, err := strconv.ParseFloat(query.Get("amount"), 64)
+		if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) || amount <= 0 {
+			log.Error("Invalid amount", "amount", query.Get("amount"), "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": "Validation failed: amount must be a positive finite number"})
+			return
+		}
+
+		at, err := params.Int64(r, "at", time.Now().UnixMilli())
+		if err != nil {
+			log.Error("Invalid query parameters", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": "Invalid query parameters: " + err.Error()})
+			return
+		}
+
+		conversion, err := converter.Convert(r.Context(), amount, from, to, at)
+		if errors.Is(err, storage.ErrPriceNotFound) || errors.Is(err, storage.ErrFXRateNotFound) {
+			log.Warn("Price or fx rate not found", "from", from, "to", to, "at", at, "error", err)
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, map[string]string{"error": "Price or fx rate not found"})
+			return
+		}
+		if err != nil {
+			log.Error("Failed to convert", "from", from, "to", to, "at", at, "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to convert"})
+			return
+		}
+		render.JSON(w, r, conversion)
+	}
+}
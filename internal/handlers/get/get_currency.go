@@ -3,22 +3,27 @@ package get
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
 
 	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
 
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
 )
 
 type PriceStorage interface {
-	GetPrice(ctx context.Context, coin string, timestamp int64) (models.Coin, error)
+	GetPrice(ctx context.Context, coin, quote string, timestamp int64) (models.Coin, error)
+	GetConvertedPrice(ctx context.Context, coin, quote, convert string, timestamp int64) (models.Coin, error)
 }
 
 // @Summary Получить цену криптовалюты
-// @Description Возвращает цену криптовалюты на указанный timestamp (timestamp в миллисекундах).
+// @Description Возвращает цену криптовалюты на указанный timestamp (timestamp в миллисекундах) в валюте котировки quote (по умолчанию USD).
+// @Description Если указан convert (например EUR), цена пересчитывается по курсу фиатной валюты, действовавшему в момент цены.
+// @Description Если provenance = true, возвращаются источник цены, исходное время провайдера, время получения и задержка (без convert).
 // @ID get-coin
 // @Accept json
 // @Produce json
@@ -27,9 +32,10 @@ type PriceStorage interface {
 // @Failure 400 {object} map[string]string "error: Invalid request body"
 // @Failure 400 {object} map[string]string "error: Validation failed: coin and timestamp are required"
 // @Failure 400 {object} map[string]string "error: Failed to get price"
+// @Failure 404 {object} map[string]string "error: FX rate not found"
 // @Failure 500 {object} map[string]string "error: Failed to get price"
 // @Router /currency/price [get]
-func New(log *slog.Logger, storage PriceStorage) http.HandlerFunc {
+func New(log *slog.Logger, priceStorage PriceStorage) http.HandlerFunc {
 	validate := validator.New() // Создаем экземпляр валидатора
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -58,7 +64,20 @@ func New(log *slog.Logger, storage PriceStorage) http.HandlerFunc {
 			render.JSON(w, r, map[string]string{"error": "Failed to get price"})
 			return
 		}
-		coinInfo, err := storage.GetPrice(r.Context(), req.Coin, timestamp)
+		req.Quote = models.NormalizeQuote(req.Quote)
+		var coinInfo models.Coin
+		if req.Convert != "" && models.NormalizeQuote(req.Convert) != req.Quote {
+			coinInfo, err = priceStorage.GetConvertedPrice(r.Context(), req.Coin, req.Quote,
+				models.NormalizeQuote(req.Convert), timestamp)
+		} else {
+			coinInfo, err = priceStorage.GetPrice(r.Context(), req.Coin, req.Quote, timestamp)
+		}
+		if errors.Is(err, storage.ErrFXRateNotFound) {
+			log.Warn("FX rate not found", "coin", req.Coin, "convert", req.Convert, "timestamp", req.Timestamp, "error", err)
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, map[string]string{"error": "FX rate not found"})
+			return
+		}
 		if err != nil {
 			log.Error("Failed to get price", "coin", req.Coin, "timestamp", req.Timestamp, "error", err)
 			w.WriteHeader(http.StatusInternalServerError)
@@ -68,9 +87,13 @@ func New(log *slog.Logger, storage PriceStorage) http.HandlerFunc {
 
 		response := models.Coin{
 			Name:      coinInfo.Name,
+			Quote:     coinInfo.Quote,
 			Price:     coinInfo.Price,
 			Timestamp: coinInfo.Timestamp,
 		}
+		if req.Provenance {
+			response.Provenance = coinInfo.Provenance
+		}
 		render.JSON(w, r, response)
 	}
 }
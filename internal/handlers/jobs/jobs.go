@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+type JobStorage interface {
+	GetBackfillJob(ctx context.Context, id int64) (models.BackfillJob, error)
+}
+
+// @Summary Получить задачу загрузки истории
+// @Description Возвращает статус (queued, running, done, failed) и прогресс задачи загрузки истории цен.
+// @Description Незавершённые задачи продолжаются с сохранённой позиции после перезапуска сервиса.
+// @ID get-job
+// @Produce json
+// @Param id path int true "Идентификатор задачи"
+// @Success 200 {object} models.BackfillJob "Задача"
+// @Failure 400 {object} map[string]string "error: Invalid job id"
+// @Failure 404 {object} map[string]string "error: Job not found"
+// @Failure 500 {object} map[string]string "error: Failed to get job"
+// @Router /jobs/{id} [get]
+func NewGet(log *slog.Logger, jobStorage JobStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("Invalid job id", "id", chi.URLParam(r, "id"), "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": "Invalid job id"})
+			return
+		}
+
+		job, err := jobStorage.GetBackfillJob(r.Context(), id)
+		if errors.Is(err, storage.ErrJobNotFound) {
+			log.Warn("Job not found", "id", id)
+			w.WriteHeader(http.StatusNotFound)
+			render.JSON(w, r, map[string]string{"error": "Job not found"})
+			return
+		}
+		if err != nil {
+			log.Error("Failed to get job", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to get job"})
+			return
+		}
+		render.JSON(w, r, job)
+	}
+}
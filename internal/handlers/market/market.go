@@ -0,0 +1,261 @@
+package market
+
+import (
+	"context"
+	"crypto_tracker/internal/analytics"
+	"crypto_tracker/internal/handlers/params"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/tracker"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+var (
+	DefaultMoversWindow = 24 * time.Hour // Окно изменения цены для /v1/movers, если window не указан
+	DefaultMoversLimit  = 10             // Число растущих и падающих валют, если limit не указан
+	MaxMoversLimit      = 100
+)
+
+// SnapshotWindows - окна изменения цены в снимке рынка
+var SnapshotWindows = []struct {
+	Name     string
+	Duration time.Duration
+}{
+	{"1h", time.Hour},
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+}
+
+type PriceStorage interface {
+	GetLatestPrices(ctx context.Context, quote string, coins []string, offsets []int64) ([]models.LatestPrice, error)
+}
+
+// Names возвращает название, под которым хранятся цены отслеживаемой валюты
+type Names interface {
+	Name(coin string) string
+}
+
+// @Summary Снимок рынка
+// @Description Возвращает последнюю сохранённую цену каждой отслеживаемой в котировке quote валюты и её изменение
+// @Description в процентах за 1h, 24h и 7d (относительно последней цены не позже времени последней цены минус окно).
+// @ID get-snapshot
+// @Produce json
+// @Param coins query string false "Валюты через запятую (по умолчанию все отслеживаемые)"
+// @Param quote query string false "Котировка цен (по умолчанию USD)"
+// @Param sort query string false "Сортировка: coin, price, change_1h, change_24h, change_7d (по умолчанию coin)"
+// @Param order query string false "Порядок: asc, desc (по умолчанию asc)"
+// @Success 200 {array} models.SnapshotEntry "Снимок рынка"
+// @Failure 400 {object} map[string]string "error: Invalid query parameters"
+// @Failure 500 {object} map[string]string "error: Failed to get snapshot"
+// @Router /v1/snapshot [get]
+func NewSnapshot(log *slog.Logger, priceStorage PriceStorage, names Names) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		quote := models.NormalizeQuote(query.Get("quote"))
+
+		less, err := snapshotOrder(query.Get("sort"), query.Get("order"))
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+
+		offsets := make([]int64, len(SnapshotWindows))
+		for i, window := range SnapshotWindows {
+			offsets[i] = window.Duration.Milliseconds()
+		}
+		prices, err := latestPrices(r.Context(), priceStorage, names, quote, params.List(r, "coins"), offsets)
+		if err != nil {
+			log.Error("Failed to get snapshot", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to get snapshot"})
+			return
+		}
+
+		entries := make([]models.SnapshotEntry, 0, len(prices))
+		for _, price := range prices {
+			entry := models.SnapshotEntry{
+				Coin:      price.Name,
+				Quote:     price.Quote,
+				Price:     price.Price,
+				Timestamp: price.Timestamp,
+				Changes:   make(map[string]*float64, len(SnapshotWindows)),
+			}
+			for i, window := range SnapshotWindows {
+				entry.Changes[window.Name] = change(price, i)
+			}
+			entries = append(entries, entry)
+		}
+		sort.SliceStable(entries, func(i, j int) bool { return less(entries[i], entries[j]) })
+		render.JSON(w, r, entries)
+	}
+}
+
+// @Summary Лидеры роста и падения
+// @Description Возвращает limit отслеживаемых в котировке quote валют с наибольшим ростом и limit валют
+// @Description с наибольшим падением цены за window.
+// @ID get-movers
+// @Produce json
+// @Param window query string false "Окно (по умолчанию 24h)"
+// @Param limit query int false "Число валют в каждом списке (по умолчанию 10, не больше 100)"
+// @Param quote query string false "Котировка цен (по умолчанию USD)"
+// @Success 200 {object} models.Movers "Лидеры роста и падения"
+// @Failure 400 {object} map[string]string "error: Invalid query parameters"
+// @Failure 500 {object} map[string]string "error: Failed to get movers"
+// @Router /v1/movers [get]
+func NewMovers(log *slog.Logger, priceStorage PriceStorage, names Names) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		quote := models.NormalizeQuote(query.Get("quote"))
+
+		window, err := params.Duration(r, "window", DefaultMoversWindow)
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		limit, err := params.Int64(r, "limit", int64(DefaultMoversLimit))
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		if limit <= 0 || limit > int64(MaxMoversLimit) {
+			badRequest(log, w, r, errors.New("limit must be between 1 and 100"))
+			return
+		}
+
+		prices, err := latestPrices(r.Context(), priceStorage, names, quote, nil, []int64{window.Milliseconds()})
+		if err != nil {
+			log.Error("Failed to get movers", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to get movers"})
+			return
+		}
+
+		movers := make([]models.Mover, 0, len(prices))
+		for _, price := range prices {
+			pct := change(price, 0)
+			if pct == nil {
+				continue
+			}
+			movers = append(movers, models.Mover{
+				Coin:           price.Name,
+				Quote:          price.Quote,
+				Price:          price.Price,
+				Timestamp:      price.Timestamp,
+				ReferencePrice: *price.Previous[0],
+				Change:         *pct,
+			})
+		}
+		sort.SliceStable(movers, func(i, j int) bool { return movers[i].Change > movers[j].Change })
+
+		result := models.Movers{
+			Window:  window.String(),
+			Quote:   quote,
+			Gainers: make([]models.Mover, 0, limit),
+			Losers:  make([]models.Mover, 0, limit),
+		}
+		if value := query.Get("window"); value != "" {
+			result.Window = value
+		}
+		for _, mover := range movers {
+			if mover.Change > 0 && len(result.Gainers) < int(limit) {
+				result.Gainers = append(result.Gainers, mover)
+			}
+		}
+		for i := len(movers) - 1; i >= 0; i-- {
+			if movers[i].Change < 0 && len(result.Losers) < int(limit) {
+				result.Losers = append(result.Losers, movers[i])
+			}
+		}
+		render.JSON(w, r, result)
+	}
+}
+
+// latestPrices возвращает последние цены отслеживаемых в котировке quote валют: всех или только из coins.
+// Валюты, удалённые из отслеживаемых, не возвращаются, даже если их цены остались в базе.
+func latestPrices(ctx context.Context, priceStorage PriceStorage, names Names, quote string, coins []string,
+	offsets []int64) ([]models.LatestPrice, error) {
+	tracked := make(map[string]bool)
+	for _, pair := range tracker.Tracked() {
+		if pair.Quote == quote {
+			tracked[names.Name(pair.Coin)] = true
+		}
+	}
+
+	watchlist := make([]string, 0, len(tracked))
+	if len(coins) == 0 {
+		for name := range tracked {
+			watchlist = append(watchlist, name)
+		}
+	}
+	for _, coin := range coins {
+		if name := names.Name(coin); tracked[name] {
+			watchlist = append(watchlist, name)
+		}
+	}
+	if len(watchlist) == 0 {
+		return make([]models.LatestPrice, 0), nil
+	}
+	return priceStorage.GetLatestPrices(ctx, quote, watchlist, offsets)
+}
+
+// change возвращает изменение последней цены относительно i-й предшествующей цены в процентах
+func change(price models.LatestPrice, i int) *float64 {
+	if i >= len(price.Previous) || price.Previous[i] == nil || *price.Previous[i] == 0 {
+		return nil
+	}
+	pct := analytics.PercentChange(*price.Previous[i], price.Price)
+	return &pct
+}
+
+// snapshotOrder возвращает функцию сравнения для сортировки снимка. Записи без изменения идут в конце.
+func snapshotOrder(field, order string) (func(a, b models.SnapshotEntry) bool, error) {
+	if order != "" && order != "asc" && order != "desc" {
+		return nil, errors.New("order must be asc or desc")
+	}
+	desc := order == "desc"
+
+	var key func(e models.SnapshotEntry) *float64
+	switch field {
+	case "", "coin":
+		if desc {
+			return func(a, b models.SnapshotEntry) bool { return a.Coin > b.Coin }, nil
+		}
+		return func(a, b models.SnapshotEntry) bool { return a.Coin < b.Coin }, nil
+	case "price":
+		key = func(e models.SnapshotEntry) *float64 { return &e.Price }
+	default:
+		found := false
+		for _, window := range SnapshotWindows {
+			if field == "change_"+window.Name {
+				name := window.Name
+				key = func(e models.SnapshotEntry) *float64 { return e.Changes[name] }
+				found = true
+			}
+		}
+		if !found {
+			return nil, errors.New("sort must be one of coin, price, change_1h, change_24h, change_7d")
+		}
+	}
+
+	return func(a, b models.SnapshotEntry) bool {
+		x, y := key(a), key(b)
+		if x == nil || y == nil {
+			return x != nil
+		}
+		if desc {
+			return *x > *y
+		}
+		return *x < *y
+	}, nil
+}
+
+func badRequest(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	log.Error("Invalid query parameters", "error", err)
+	w.WriteHeader(http.StatusBadRequest)
+	render.JSON(w, r, map[string]string{"error": "Invalid query parameters: " + err.Error()})
+}
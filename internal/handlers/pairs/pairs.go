@@ -0,0 +1,135 @@
+package pairs
+
+import (
+	"context"
+	"crypto_tracker/internal/handlers/params"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+var (
+	DefaultTolerance = time.Minute       // Допустимое расхождение времени цен двух валют
+	DefaultPeriod    = 24 * time.Hour    // Период истории, если from не указан
+	MaxPeriod        = 7 * DefaultPeriod // Максимальный период истории: курс считается для каждой цены base
+)
+
+type CrossRates interface {
+	Cross(ctx context.Context, base, quote, via string, at int64, tolerance time.Duration) (models.CrossRate, error)
+	CrossHistory(ctx context.Context, base, quote, via string, from, to int64,
+		tolerance time.Duration) (models.CrossRateHistory, error)
+}
+
+// @Summary Кросс-курс двух отслеживаемых валют
+// @Description Вычисляет курс base/quote (например Ethereum/Bitcoin) по ценам обеих валют в котировке via (по умолчанию USD).
+// @Description Для каждой валюты берётся цена, ближайшая к at, но не дальше tolerance; иначе возвращается 404.
+// @ID get-pair-price
+// @Produce json
+// @Param base path string true "Базовая валюта (например Ethereum)"
+// @Param quote path string true "Котируемая валюта (например Bitcoin)"
+// @Param at query int false "Timestamp в миллисекундах (по умолчанию текущее время)"
+// @Param tolerance query string false "Допустимое расхождение времени цен (по умолчанию 1m)"
+// @Param via query string false "Общая котировка (по умолчанию USD)"
+// @Success 200 {object} models.CrossRate "Кросс-курс"
+// @Failure 400 {object} map[string]string "error: Invalid query parameters"
+// @Failure 404 {object} map[string]string "error: No price within tolerance"
+// @Failure 500 {object} map[string]string "error: Failed to get cross rate"
+// @Router /v1/pairs/{base}/{quote}/price [get]
+func NewPrice(log *slog.Logger, crossRates CrossRates) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		base, quote := chi.URLParam(r, "base"), chi.URLParam(r, "quote")
+		via := models.NormalizeQuote(r.URL.Query().Get("via"))
+
+		at, err := params.Int64(r, "at", time.Now().UnixMilli())
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		tolerance, err := params.Duration(r, "tolerance", DefaultTolerance)
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+
+		rate, err := crossRates.Cross(r.Context(), base, quote, via, at, tolerance)
+		if err != nil {
+			renderError(log, w, r, err, "Failed to get cross rate")
+			return
+		}
+		render.JSON(w, r, rate)
+	}
+}
+
+// @Summary История кросс-курса двух отслеживаемых валют
+// @Description Вычисляет курс base/quote для каждой цены base за период [from, to], выравнивая по времени цены quote в пределах tolerance.
+// @Description Цены base без пары пропускаются (поле skipped). Если выровненных цен нет, возвращается 404.
+// @ID get-pair-history
+// @Produce json
+// @Param base path string true "Базовая валюта (например Ethereum)"
+// @Param quote path string true "Котируемая валюта (например Bitcoin)"
+// @Param from query int false "Начало периода, timestamp в миллисекундах (по умолчанию to - 24h, период не больше 7d)"
+// @Param to query int false "Конец периода, timestamp в миллисекундах (по умолчанию текущее время)"
+// @Param tolerance query string false "Допустимое расхождение времени цен (по умолчанию 1m)"
+// @Param via query string false "Общая котировка (по умолчанию USD)"
+// @Success 200 {object} models.CrossRateHistory "История кросс-курса"
+// @Failure 400 {object} map[string]string "error: Invalid query parameters"
+// @Failure 404 {object} map[string]string "error: No price within tolerance"
+// @Failure 500 {object} map[string]string "error: Failed to get cross rate history"
+// @Router /v1/pairs/{base}/{quote}/history [get]
+func NewHistory(log *slog.Logger, crossRates CrossRates) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		base, quote := chi.URLParam(r, "base"), chi.URLParam(r, "quote")
+		via := models.NormalizeQuote(r.URL.Query().Get("via"))
+
+		to, err := params.Int64(r, "to", time.Now().UnixMilli())
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		from, err := params.Int64(r, "from", to-DefaultPeriod.Milliseconds())
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		tolerance, err := params.Duration(r, "tolerance", DefaultTolerance)
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		if from > to || to-from > MaxPeriod.Milliseconds() {
+			badRequest(log, w, r, errors.New("from must not exceed to and the period must be at most 7d"))
+			return
+		}
+
+		history, err := crossRates.CrossHistory(r.Context(), base, quote, via, from, to, tolerance)
+		if err != nil {
+			renderError(log, w, r, err, "Failed to get cross rate history")
+			return
+		}
+		render.JSON(w, r, history)
+	}
+}
+
+func badRequest(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	log.Error("Invalid query parameters", "error", err)
+	w.WriteHeader(http.StatusBadRequest)
+	render.JSON(w, r, map[string]string{"error": "Invalid query parameters: " + err.Error()})
+}
+
+func renderError(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error, message string) {
+	if errors.Is(err, storage.ErrPriceNotFound) {
+		log.Warn("No price within tolerance", "error", err)
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "No price within tolerance"})
+		return
+	}
+	log.Error(message, "error", err)
+	w.WriteHeader(http.StatusInternalServerError)
+	render.JSON(w, r, map[string]string{"error": message})
+}
@@ -0,0 +1,96 @@
+package params
+
+import (
+	"crypto_tracker/internal/models"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Int64 читает целочисленный query-параметр name, возвращая def, если он не задан
+func Int64(r *http.Request, name string, def int64) (int64, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return n, nil
+}
+
+// Duration читает положительный query-параметр name вида 1m, 24h, возвращая def, если он не задан.
+// Помимо формата time.ParseDuration поддерживаются дни: 7d, 30d.
+func Duration(r *http.Request, name string, def time.Duration) (time.Duration, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+	d, err := ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid %s: must be positive", name)
+	}
+	return d, nil
+}
+
+// ParseDuration работает как time.ParseDuration, но также понимает дни (7d)
+func ParseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// List читает query-параметр name со значениями через запятую
+func List(r *http.Request, name string) []string {
+	values := make([]string, 0)
+	for _, value := range strings.Split(r.URL.Query().Get(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Resample читает параметры приведения к сетке: step (по умолчанию defStep),
+// fill (none, previous, linear, zero; по умолчанию previous) и agg (last, mean, first; по умолчанию last)
+func Resample(r *http.Request, defStep time.Duration) (models.Resample, error) {
+	step, err := Duration(r, "step", defStep)
+	if err != nil {
+		return models.Resample{}, err
+	}
+	resample := models.Resample{
+		Step: step.Milliseconds(),
+		Fill: r.URL.Query().Get("fill"),
+		Agg:  r.URL.Query().Get("agg"),
+	}
+	if resample.Step <= 0 {
+		return models.Resample{}, fmt.Errorf("invalid step: must be at least 1ms")
+	}
+
+	switch resample.Fill {
+	case "":
+		resample.Fill = models.FillPrevious
+	case models.FillNone, models.FillPrevious, models.FillLinear, models.FillZero:
+	default:
+		return models.Resample{}, fmt.Errorf("invalid fill: must be one of none, previous, linear, zero")
+	}
+	switch resample.Agg {
+	case "":
+		resample.Agg = models.AggLast
+	case models.AggLast, models.AggMean, models.AggFirst:
+	default:
+		return models.Resample{}, fmt.Errorf("invalid agg: must be one of last, mean, first")
+	}
+	return resample, nil
+}
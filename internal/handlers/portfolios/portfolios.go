@@ -0,0 +1,416 @@
+package portfolios
+
+import (
+	"context"
+	"crypto_tracker/internal/handlers/params"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"crypto_tracker/internal/tracker"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	DefaultPeriod = 24 * time.Hour // Период истории стоимости, если from не указан
+	DefaultStep   = time.Hour      // Шаг истории стоимости, если step не указан
+	MaxPoints     = 1000           // Максимальное число точек истории стоимости
+)
+
+type PortfolioStorage interface {
+	CreatePortfolio(ctx context.Context, portfolio models.Portfolio) (int64, error)
+	GetPortfolios(ctx context.Context) ([]models.Portfolio, error)
+	GetPortfolio(ctx context.Context, id int64) (models.Portfolio, error)
+	DeletePortfolio(ctx context.Context, id int64) error
+	SetHolding(ctx context.Context, portfolioID int64, holding models.Holding) error
+	DeleteHolding(ctx context.Context, portfolioID int64, coin string) error
+	GetPortfolioValues(ctx context.Context, id int64, quote string, from, to int64,
+		resample models.Resample) ([]models.PortfolioValue, error)
+}
+
+type Watcher interface {
+	Watch(ctx context.Context, coin, quote string) error
+}
+
+// @Summary Создать портфель
+// @Description Создаёт пустой портфель.
+// @ID create-portfolio
+// @Accept json
+// @Produce json
+// @Param request body models.PortfolioRequest true "Портфель"
+// @Success 201 {object} models.Portfolio "Созданный портфель"
+// @Failure 400 {object} map[string]string "error: Invalid request body"
+// @Failure 500 {object} map[string]string "error: Failed to create portfolio"
+// @Router /v1/portfolios [post]
+func NewCreate(log *slog.Logger, portfolioStorage PortfolioStorage) http.HandlerFunc {
+	validate := validator.New()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.PortfolioRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || validate.Struct(req) != nil {
+			log.Error("Invalid request body", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": "Invalid request body: name is required"})
+			return
+		}
+
+		portfolio := models.Portfolio{
+			Name:      req.Name,
+			CreatedAt: time.Now().UnixMilli(),
+			Holdings:  make([]models.Holding, 0),
+		}
+		id, err := portfolioStorage.CreatePortfolio(r.Context(), portfolio)
+		if err != nil {
+			log.Error("Failed to create portfolio", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to create portfolio"})
+			return
+		}
+		portfolio.ID = id
+
+		w.WriteHeader(http.StatusCreated)
+		render.JSON(w, r, portfolio)
+	}
+}
+
+// @Summary Список портфелей
+// @Description Возвращает все портфели с позициями.
+// @ID list-portfolios
+// @Produce json
+// @Success 200 {array} models.Portfolio "Портфели"
+// @Failure 500 {object} map[string]string "error: Failed to get portfolios"
+// @Router /v1/portfolios [get]
+func NewList(log *slog.Logger, portfolioStorage PortfolioStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		portfolios, err := portfolioStorage.GetPortfolios(r.Context())
+		if err != nil {
+			log.Error("Failed to get portfolios", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Failed to get portfolios"})
+			return
+		}
+		render.JSON(w, r, portfolios)
+	}
+}
+
+// @Summary Получить портфель
+// @Description Возвращает портфель с позициями.
+// @ID get-portfolio
+// @Produce json
+// @Param id path int true "Идентификатор портфеля"
+// @Success 200 {object} models.Portfolio "Портфель"
+// @Failure 400 {object} map[string]string "error: Invalid portfolio id"
+// @Failure 404 {object} map[string]string "error: Portfolio not found"
+// @Failure 500 {object} map[string]string "error: Failed to get portfolio"
+// @Router /v1/portfolios/{id} [get]
+func NewGet(log *slog.Logger, portfolioStorage PortfolioStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := parseID(log, w, r)
+		if !ok {
+			return
+		}
+
+		portfolio, err := portfolioStorage.GetPortfolio(r.Context(), id)
+		if err != nil {
+			renderStorageError(log, w, r, err, "Failed to get portfolio")
+			return
+		}
+		render.JSON(w, r, portfolio)
+	}
+}
+
+// @Summary Удалить портфель
+// @Description Удаляет портфель вместе с позициями.
+// @ID delete-portfolio
+// @Produce json
+// @Param id path int true "Идентификатор портфеля"
+// @Success 200 {object} map[string]string "message: Portfolio deleted"
+// @Failure 400 {object} map[string]string "error: Invalid portfolio id"
+// @Failure 404 {object} map[string]string "error: Portfolio not found"
+// @Failure 500 {object} map[string]string "error: Failed to delete portfolio"
+// @Router /v1/portfolios/{id} [delete]
+func NewDelete(log *slog.Logger, portfolioStorage PortfolioStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := parseID(log, w, r)
+		if !ok {
+			return
+		}
+
+		if err := portfolioStorage.DeletePortfolio(r.Context(), id); err != nil {
+			renderStorageError(log, w, r, err, "Failed to delete portfolio")
+			return
+		}
+		render.JSON(w, r, map[string]string{"message": "Portfolio deleted"})
+	}
+}
+
+// @Summary Добавить или изменить позицию
+// @Description Задаёт количество валюты в портфеле. Если track = true и валюта не отслеживается,
+// @Description она добавляется в список отслеживаемых так же, как через /currency/add (котировка USD).
+// @Description Если провайдер недоступен, позиция не сохраняется: возвращается 502 или 503 с видом ошибки kind.
+// @ID set-holding
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор портфеля"
+// @Param request body models.HoldingRequest true "Позиция"
+// @Success 200 {object} models.Portfolio "Портфель"
+// @Failure 400 {object} map[string]string "error: Invalid request body"
+// @Failure 400 {object} map[string]string "error: Invalid coin"
+// @Failure 404 {object} map[string]string "error: Portfolio not found"
+// @Failure 500 {object} map[string]string "error: Failed to set holding"
+// @Failure 500 {object} map[string]string "error: Provider misconfigured"
+// @Failure 502 {object} map[string]string "error: Provider unavailable, kind: вид ошибки провайдера"
+// @Failure 503 {object} map[string]string "error: Provider rate limit exceeded, kind: rate_limited"
+// @Router /v1/portfolios/{id}/holdings [put]
+func NewSetHolding(log *slog.Logger, portfolioStorage PortfolioStorage, watcher Watcher) http.HandlerFunc {
+	validate := validator.New()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := parseID(log, w, r)
+		if !ok {
+			return
+		}
+
+		var req models.HoldingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || validate.Struct(req) != nil {
+			log.Error("Invalid request body", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": "Invalid request body: coin and non-negative quantity are required"})
+			return
+		}
+		req.Coin = strings.TrimSpace(req.Coin)
+
+		if _, err := portfolioStorage.GetPortfolio(r.Context(), id); err != nil {
+			renderStorageError(log, w, r, err, "Failed to set holding")
+			return
+		}
+
+		if req.Track {
+			err := watcher.Watch(r.Context(), req.Coin, models.DefaultQuote)
+			if errors.Is(err, tracker.ErrInvalidCoin) {
+				log.Warn("Invalid coin", "coin", req.Coin)
+				w.WriteHeader(http.StatusBadRequest)
+				render.JSON(w, r, map[string]string{"error": "Invalid coin"})
+				return
+			}
+			if err == nil {
+				log.Info("Currency added to watchlist from portfolio", "coin", req.Coin, "portfolio", id)
+			}
+			if err != nil && !errors.Is(err, tracker.ErrAlreadyTracked) {
+				renderWatchError(log, w, r, req.Coin, err)
+				return
+			}
+		}
+
+		holding := models.Holding{Coin: req.Coin, Quantity: req.Quantity}
+		if err := portfolioStorage.SetHolding(r.Context(), id, holding); err != nil {
+			renderStorageError(log, w, r, err, "Failed to set holding")
+			return
+		}
+
+		portfolio, err := portfolioStorage.GetPortfolio(r.Context(), id)
+		if err != nil {
+			renderStorageError(log, w, r, err, "Failed to get portfolio")
+			return
+		}
+		render.JSON(w, r, portfolio)
+	}
+}
+
+// @Summary Удалить позицию
+// @Description Удаляет валюту из портфеля. Сбор цен валюты не останавливается.
+// @ID delete-holding
+// @Produce json
+// @Param id path int true "Идентификатор портфеля"
+// @Param coin path string true "Валюта"
+// @Success 200 {object} map[string]string "message: Holding deleted"
+// @Failure 400 {object} map[string]string "error: Invalid portfolio id"
+// @Failure 404 {object} map[string]string "error: Holding not found"
+// @Failure 500 {object} map[string]string "error: Failed to delete holding"
+// @Router /v1/portfolios/{id}/holdings/{coin} [delete]
+func NewDeleteHolding(log *slog.Logger, portfolioStorage PortfolioStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := parseID(log, w, r)
+		if !ok {
+			return
+		}
+
+		if err := portfolioStorage.DeleteHolding(r.Context(), id, chi.URLParam(r, "coin")); err != nil {
+			renderStorageError(log, w, r, err, "Failed to delete holding")
+			return
+		}
+		render.JSON(w, r, map[string]string{"message": "Holding deleted"})
+	}
+}
+
+// @Summary Стоимость портфеля
+// @Description Оценивает портфель в момент at по последним ценам позиций не позже at.
+// @Description Позиции без цены перечисляются в missing и не входят в total.
+// @ID get-portfolio-value
+// @Produce json
+// @Param id path int true "Идентификатор портфеля"
+// @Param at query int false "Timestamp в миллисекундах (по умолчанию текущее время)"
+// @Param quote query string false "Котировка цен (по умолчанию USD)"
+// @Success 200 {object} models.PortfolioValue "Стоимость портфеля"
+// @Failure 400 {object} map[string]string "error: Invalid query parameters"
+// @Failure 404 {object} map[string]string "error: Portfolio not found"
+// @Failure 500 {object} map[string]string "error: Failed to get portfolio value"
+// @Router /v1/portfolios/{id}/value [get]
+func NewValue(log *slog.Logger, portfolioStorage PortfolioStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := parseID(log, w, r)
+		if !ok {
+			return
+		}
+		quote := models.NormalizeQuote(r.URL.Query().Get("quote"))
+
+		at, err := params.Int64(r, "at", time.Now().UnixMilli())
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+
+		// Последняя цена не позже at
+		resample := models.Resample{Step: 1, Fill: models.FillPrevious, Agg: models.AggLast}
+		values, err := portfolioStorage.GetPortfolioValues(r.Context(), id, quote, at, at, resample)
+		if err != nil {
+			renderStorageError(log, w, r, err, "Failed to get portfolio value")
+			return
+		}
+
+		// В пустом портфеле нет позиций для оценки
+		value := models.PortfolioValue{Timestamp: at, Quote: quote, Holdings: make([]models.HoldingValue, 0)}
+		if len(values) > 0 {
+			value = values[0]
+		}
+		render.JSON(w, r, value)
+	}
+}
+
+// @Summary История стоимости портфеля
+// @Description Оценивает портфель в моменты from, from+step, ..., to (не более 1000 точек).
+// @Description Цена позиции в момент t агрегируется (agg) из цен интервала (t - step, t], пустые интервалы заполняются (fill).
+// @ID get-portfolio-value-history
+// @Produce json
+// @Param id path int true "Идентификатор портфеля"
+// @Param from query int false "Начало периода, timestamp в миллисекундах (по умолчанию to - 24h)"
+// @Param to query int false "Конец периода, timestamp в миллисекундах (по умолчанию текущее время)"
+// @Param step query string false "Шаг (по умолчанию 1h)"
+// @Param fill query string false "Заполнение пустых интервалов: none, previous, linear, zero (по умолчанию previous)"
+// @Param agg query string false "Агрегация цен в интервале: last, mean, first (по умолчанию last)"
+// @Param quote query string false "Котировка цен (по умолчанию USD)"
+// @Success 200 {array} models.PortfolioValue "Стоимость портфеля по времени"
+// @Failure 400 {object} map[string]string "error: Invalid query parameters"
+// @Failure 404 {object} map[string]string "error: Portfolio not found"
+// @Failure 500 {object} map[string]string "error: Failed to get portfolio value"
+// @Router /v1/portfolios/{id}/value/history [get]
+func NewValueHistory(log *slog.Logger, portfolioStorage PortfolioStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := parseID(log, w, r)
+		if !ok {
+			return
+		}
+		quote := models.NormalizeQuote(r.URL.Query().Get("quote"))
+
+		to, err := params.Int64(r, "to", time.Now().UnixMilli())
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		from, err := params.Int64(r, "from", to-DefaultPeriod.Milliseconds())
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		resample, err := params.Resample(r, DefaultStep)
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		if from > to || (to-from)/resample.Step >= int64(MaxPoints) {
+			badRequest(log, w, r, errors.New("from must not exceed to and the period must contain at most 1000 steps"))
+			return
+		}
+
+		values, err := portfolioStorage.GetPortfolioValues(r.Context(), id, quote, from, to, resample)
+		if err != nil {
+			renderStorageError(log, w, r, err, "Failed to get portfolio value")
+			return
+		}
+		// Для истории достаточно итоговой стоимости
+		for i := range values {
+			values[i].Holdings = nil
+		}
+		render.JSON(w, r, values)
+	}
+}
+
+func parseID(log *slog.Logger, w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		log.Error("Invalid portfolio id", "id", chi.URLParam(r, "id"), "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Invalid portfolio id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func badRequest(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	log.Error("Invalid query parameters", "error", err)
+	w.WriteHeader(http.StatusBadRequest)
+	render.JSON(w, r, map[string]string{"error": "Invalid query parameters: " + err.Error()})
+}
+
+func renderStorageError(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.Is(err, storage.ErrPortfolioNotFound):
+		log.Warn("Portfolio not found", "error", err)
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Portfolio not found"})
+	case errors.Is(err, storage.ErrHoldingNotFound):
+		log.Warn("Holding not found", "error", err)
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Holding not found"})
+	default:
+		log.Error(message, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": message})
+	}
+}
+
+// renderWatchError отвечает на ошибку добавления валюты в список отслеживаемых: 503, если провайдер ограничил
+// частоту запросов, 502 при остальных его ошибках и 500, если провайдер отклонил ключ API или ошибка внутренняя
+func renderWatchError(log *slog.Logger, w http.ResponseWriter, r *http.Request, coin string, err error) {
+	kind := tracker.ErrorKind(err)
+	log.Warn("Failed to add currency to watchlist", "coin", coin, "kind", kind, "error", err)
+
+	var providerErr *tracker.ProviderError
+	switch {
+	case errors.Is(err, tracker.ErrUnauthorized):
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Provider misconfigured", "kind": kind})
+	case errors.As(err, &providerErr):
+		if providerErr.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(providerErr.RetryAfter.Seconds())))
+		}
+		if errors.Is(err, tracker.ErrRateLimited) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			render.JSON(w, r, map[string]string{"error": "Provider rate limit exceeded", "kind": kind})
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+		render.JSON(w, r, map[string]string{"error": "Provider unavailable", "kind": kind})
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": "Failed to add currency to watchlist", "kind": kind})
+	}
+}
@@ -0,0 +1,226 @@
+package portfolios
+
+import (
+	"context"
+	"crypto_tracker/internal/handlers/params"
+	"crypto_tracker/internal/ledger"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+var DefaultTolerance = 5 * time.Minute // Допустимое расстояние до цены из истории, если tolerance не указан
+
+type Ledger interface {
+	Record(ctx context.Context, portfolioID int64, req models.TransactionRequest, tolerance time.Duration) (models.Transaction, error)
+	Delete(ctx context.Context, portfolioID, id int64) error
+	Transactions(ctx context.Context, portfolioID int64) ([]models.Transaction, error)
+	PnL(ctx context.Context, portfolioID, at int64, method string, tolerance time.Duration) (models.PortfolioPnL, error)
+}
+
+// @Summary Добавить транзакцию
+// @Description Записывает покупку, продажу, поступление или вывод валюты. Цена и комиссия - в USD.
+// @Description Если цена не указана, берётся ближайшая к timestamp цена из истории не дальше tolerance.
+// @Description Транзакция, после которой количество валюты станет отрицательным, отклоняется.
+// @ID create-transaction
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор портфеля"
+// @Param tolerance query string false "Допустимое расстояние до цены из истории (по умолчанию 5m)"
+// @Param request body models.TransactionRequest true "Транзакция"
+// @Success 201 {object} models.Transaction "Записанная транзакция"
+// @Failure 400 {object} map[string]string "error: Invalid request body"
+// @Failure 400 {object} map[string]string "error: Insufficient quantity"
+// @Failure 404 {object} map[string]string "error: Portfolio not found"
+// @Failure 404 {object} map[string]string "error: No price within tolerance"
+// @Failure 500 {object} map[string]string "error: Failed to create transaction"
+// @Router /v1/portfolios/{id}/transactions [post]
+func NewCreateTransaction(log *slog.Logger, portfolioStorage PortfolioStorage, transactions Ledger) http.HandlerFunc {
+	validate := validator.New()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := parseID(log, w, r)
+		if !ok {
+			return
+		}
+		tolerance, err := params.Duration(r, "tolerance", DefaultTolerance)
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+
+		var req models.TransactionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || validate.Struct(req) != nil {
+			log.Error("Invalid request body", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{
+				"error": "Invalid request body: coin, type, positive quantity and timestamp are required",
+			})
+			return
+		}
+
+		if _, err := portfolioStorage.GetPortfolio(r.Context(), id); err != nil {
+			renderStorageError(log, w, r, err, "Failed to create transaction")
+			return
+		}
+
+		tx, err := transactions.Record(r.Context(), id, req, tolerance)
+		if err != nil {
+			renderLedgerError(log, w, r, err, "Failed to create transaction")
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		render.JSON(w, r, tx)
+	}
+}
+
+// @Summary Список транзакций
+// @Description Возвращает транзакции портфеля в хронологическом порядке.
+// @ID list-transactions
+// @Produce json
+// @Param id path int true "Идентификатор портфеля"
+// @Success 200 {array} models.Transaction "Транзакции"
+// @Failure 400 {object} map[string]string "error: Invalid portfolio id"
+// @Failure 404 {object} map[string]string "error: Portfolio not found"
+// @Failure 500 {object} map[string]string "error: Failed to get transactions"
+// @Router /v1/portfolios/{id}/transactions [get]
+func NewListTransactions(log *slog.Logger, portfolioStorage PortfolioStorage, transactions Ledger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := parseID(log, w, r)
+		if !ok {
+			return
+		}
+
+		if _, err := portfolioStorage.GetPortfolio(r.Context(), id); err != nil {
+			renderStorageError(log, w, r, err, "Failed to get transactions")
+			return
+		}
+
+		txs, err := transactions.Transactions(r.Context(), id)
+		if err != nil {
+			renderStorageError(log, w, r, err, "Failed to get transactions")
+			return
+		}
+		render.JSON(w, r, txs)
+	}
+}
+
+// @Summary Удалить транзакцию
+// @Description Удаляет транзакцию, если без неё количество валюты нигде в журнале не становится отрицательным.
+// @ID delete-transaction
+// @Produce json
+// @Param id path int true "Идентификатор портфеля"
+// @Param tx path int true "Идентификатор транзакции"
+// @Success 200 {object} map[string]string "message: Transaction deleted"
+// @Failure 400 {object} map[string]string "error: Invalid transaction id"
+// @Failure 400 {object} map[string]string "error: Insufficient quantity"
+// @Failure 404 {object} map[string]string "error: Transaction not found"
+// @Failure 500 {object} map[string]string "error: Failed to delete transaction"
+// @Router /v1/portfolios/{id}/transactions/{tx} [delete]
+func NewDeleteTransaction(log *slog.Logger, transactions Ledger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := parseID(log, w, r)
+		if !ok {
+			return
+		}
+		txID, err := strconv.ParseInt(chi.URLParam(r, "tx"), 10, 64)
+		if err != nil {
+			log.Error("Invalid transaction id", "tx", chi.URLParam(r, "tx"), "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, map[string]string{"error": "Invalid transaction id"})
+			return
+		}
+
+		if err := transactions.Delete(r.Context(), id, txID); err != nil {
+			renderLedgerError(log, w, r, err, "Failed to delete transaction")
+			return
+		}
+		render.JSON(w, r, map[string]string{"message": "Transaction deleted"})
+	}
+}
+
+// @Summary Прибыль и убыток портфеля
+// @Description Восстанавливает позиции по транзакциям не позже at и считает себестоимость методом fifo, lifo или average.
+// @Description Реализованная прибыль - по продажам, нереализованная - по открытым позициям,
+// @Description оценённым по последней цене из истории не позже at и не раньше at - tolerance. Суммы - в USD.
+// @ID get-portfolio-pnl
+// @Produce json
+// @Param id path int true "Идентификатор портфеля"
+// @Param at query int false "Timestamp в миллисекундах (по умолчанию текущее время)"
+// @Param method query string false "Метод себестоимости: fifo, lifo, average (по умолчанию fifo)"
+// @Param tolerance query string false "Допустимое расстояние до цены из истории (по умолчанию 5m)"
+// @Success 200 {object} models.PortfolioPnL "Прибыль и убыток"
+// @Failure 400 {object} map[string]string "error: Invalid query parameters"
+// @Failure 404 {object} map[string]string "error: Portfolio not found"
+// @Failure 404 {object} map[string]string "error: No price within tolerance"
+// @Failure 500 {object} map[string]string "error: Failed to get PnL"
+// @Router /v1/portfolios/{id}/pnl [get]
+func NewPnL(log *slog.Logger, portfolioStorage PortfolioStorage, transactions Ledger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := parseID(log, w, r)
+		if !ok {
+			return
+		}
+
+		at, err := params.Int64(r, "at", time.Now().UnixMilli())
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		tolerance, err := params.Duration(r, "tolerance", DefaultTolerance)
+		if err != nil {
+			badRequest(log, w, r, err)
+			return
+		}
+		method := r.URL.Query().Get("method")
+		switch method {
+		case "":
+			method = ledger.MethodFIFO
+		case ledger.MethodFIFO, ledger.MethodLIFO, ledger.MethodAverage:
+		default:
+			badRequest(log, w, r, errors.New("method must be one of fifo, lifo, average"))
+			return
+		}
+
+		if _, err := portfolioStorage.GetPortfolio(r.Context(), id); err != nil {
+			renderStorageError(log, w, r, err, "Failed to get PnL")
+			return
+		}
+
+		pnl, err := transactions.PnL(r.Context(), id, at, method, tolerance)
+		if err != nil {
+			renderLedgerError(log, w, r, err, "Failed to get PnL")
+			return
+		}
+		render.JSON(w, r, pnl)
+	}
+}
+
+func renderLedgerError(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.Is(err, ledger.ErrInsufficientQuantity):
+		log.Warn("Insufficient quantity", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, map[string]string{"error": "Insufficient quantity"})
+	case errors.Is(err, storage.ErrPriceNotFound):
+		log.Warn("No price within tolerance", "error", err)
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "No price within tolerance"})
+	case errors.Is(err, storage.ErrTransactionNotFound):
+		log.Warn("Transaction not found", "error", err)
+		w.WriteHeader(http.StatusNotFound)
+		render.JSON(w, r, map[string]string{"error": "Transaction not found"})
+	default:
+		renderStorageError(log, w, r, err, message)
+	}
+}
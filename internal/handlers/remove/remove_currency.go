@@ -12,7 +12,7 @@ import (
 )
 
 // @Summary Удалить криптовалюту из отслеживаемых
-// @Description Удаляет криптовалюту из списка отслеживаемых и останавливает сбор данных о её цене.
+// @Description Удаляет пару криптовалюта/котировка (по умолчанию USD) из списка отслеживаемых и останавливает сбор данных о её цене.
 // @ID remove-coin
 // @Accept json
 // @Produce json
@@ -41,11 +41,14 @@ func New(log *slog.Logger) http.HandlerFunc {
 			return
 		}
 
+		req.Quote = models.NormalizeQuote(req.Quote)
+		key := tracker.Key(req.Coin, req.Quote)
+
 		//Проверяем, отслеживается ли эта криптовалюта
 		tracker.TrackedMutex.Lock()
-		if _, exists := tracker.TrackedCoins[req.Coin]; !exists {
+		if _, exists := tracker.TrackedCoins[key]; !exists {
 			tracker.TrackedMutex.Unlock()
-			log.Warn("Coin is not tracked", "coin", req.Coin)
+			log.Warn("Coin is not tracked", "coin", req.Coin, "quote", req.Quote)
 			w.WriteHeader(http.StatusNotFound)
 			render.JSON(w, r, map[string]string{"error": "Coin is not tracked"})
 			return
@@ -53,22 +56,22 @@ func New(log *slog.Logger) http.HandlerFunc {
 
 		tracker.TrackedMutex.Unlock()
 
-		stopPriceCollector(log, req.Coin)
+		stopPriceCollector(log, key)
 
 		render.JSON(w, r, map[string]string{"message": "Currency removed from watchlist"})
 	}
 }
 
-func stopPriceCollector(log *slog.Logger, coin string) {
+func stopPriceCollector(log *slog.Logger, key string) {
 	// Блокируем доступ к мапе
 	tracker.StopMutex.Lock()
 	defer tracker.StopMutex.Unlock()
 
 	// Останавливаем горутину, если канал существует
-	if stopChan, ok := tracker.StopChannels[coin]; ok {
-		close(stopChan)                    // Отправляем сигнал остановки
-		delete(tracker.StopChannels, coin) // Удаляем канал из мапы
-		log.Info("Stopped price collection for coin", "coin", coin)
-		log.Info("Map: ", "map", coin)
+	if stopChan, ok := tracker.StopChannels[key]; ok {
+		close(stopChan)                   // Отправляем сигнал остановки
+		delete(tracker.StopChannels, key) // Удаляем канал из мапы
+		log.Info("Stopped price collection for coin", "coin", key)
+		log.Info("Map: ", "map", key)
 	}
 }
@@ -0,0 +1,154 @@
+package stream
+
+import (
+	"context"
+	"crypto_tracker/internal/hub"
+	"crypto_tracker/internal/models"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+var (
+	HeartbeatInterval = 15 * time.Second // Как часто отправлять комментарий-heartbeat
+	ReplayBatch       = 500              // Сколько пропущенных цен читать из базы за один запрос
+)
+
+type Subscriber interface {
+	Subscribe(coins []string, quote string) *hub.Subscription
+	Unsubscribe(sub *hub.Subscription)
+}
+
+type PriceStorage interface {
+	GetCoinsAfter(ctx context.Context, coins []string, quote string, afterID int64, limit int) ([]models.Coin, error)
+}
+
+// @Summary Поток цен (Server-Sent Events)
+// @Description Отправляет событие price при каждом сохранении новой цены. Раз в 15 секунд отправляется комментарий-heartbeat.
+// @Description При переподключении заголовок Last-Event-ID позволяет получить из базы цены, сохранённые после этого события.
+// @ID stream-prices
+// @Produce text/event-stream
+// @Param coins query string false "Список валют через запятую (по умолчанию все)"
+// @Param quote query string false "Валюта котировки (по умолчанию USD)"
+// @Param Last-Event-ID header string false "Идентификатор последнего полученного события"
+// @Success 200 {object} models.Coin "Поток событий price"
+// @Failure 400 {object} map[string]string "error: Invalid Last-Event-ID"
+// @Failure 500 {object} map[string]string "error: Streaming unsupported"
+// @Router /stream/prices [get]
+func New(log *slog.Logger, subscriber Subscriber, storage PriceStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		coins := parseCoins(r.URL.Query().Get("coins"))
+		quote := models.NormalizeQuote(r.URL.Query().Get("quote"))
+
+		var lastID int64
+		if header := r.Header.Get("Last-Event-ID"); header != "" {
+			id, err := strconv.ParseInt(header, 10, 64)
+			if err != nil {
+				log.Error("Invalid Last-Event-ID", "id", header, "error", err)
+				w.WriteHeader(http.StatusBadRequest)
+				render.JSON(w, r, map[string]string{"error": "Invalid Last-Event-ID"})
+				return
+			}
+			lastID = id
+		}
+
+		rc := http.NewResponseController(w)
+		// Поток живёт дольше WriteTimeout сервера
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			log.Error("Streaming unsupported", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			render.JSON(w, r, map[string]string{"error": "Streaming unsupported"})
+			return
+		}
+
+		// Подписываемся до чтения истории, чтобы не потерять цены между ними
+		sub := subscriber.Subscribe(coins, quote)
+		defer subscriber.Unsubscribe(sub)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		// Досылаем цены, пропущенные с момента Last-Event-ID
+		if lastID > 0 {
+			for {
+				missed, err := storage.GetCoinsAfter(r.Context(), coins, quote, lastID, ReplayBatch)
+				if err != nil {
+					log.Error("Failed to replay prices", "last_event_id", lastID, "error", err)
+					return
+				}
+				for _, coin := range missed {
+					if err := writeEvent(w, coin); err != nil {
+						return
+					}
+					lastID = coin.ID
+				}
+				if len(missed) < ReplayBatch {
+					break
+				}
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+		// Коллекторы монет пишут параллельно, поэтому живые id приходят не по порядку:
+		// сравниваем их только с границей досылки, а не с последним отправленным id
+		replayed := lastID
+
+		heartbeat := time.NewTicker(HeartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case coin, ok := <-sub.Prices():
+				if !ok {
+					// Хаб отключил медленного клиента: он переподключится с Last-Event-ID
+					log.Warn("Price stream subscriber dropped", "coins", coins)
+					return
+				}
+				// Уже отправлено при досылке из базы
+				if coin.ID <= replayed {
+					continue
+				}
+				if err := writeEvent(w, coin); err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+					return
+				}
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, coin models.Coin) error {
+	data, err := json.Marshal(coin)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: price\ndata: %s\n\n", coin.ID, data)
+	return err
+}
+
+func parseCoins(s string) []string {
+	coins := make([]string, 0)
+	for _, coin := range strings.Split(s, ",") {
+		if coin = strings.TrimSpace(coin); coin != "" {
+			coins = append(coins, coin)
+		}
+	}
+	return coins
+}
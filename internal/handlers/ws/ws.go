@@ -0,0 +1,193 @@
+package ws
+
+import (
+	"crypto_tracker/internal/hub"
+	"crypto_tracker/internal/models"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var (
+	MaxSubscriptions = 50               // Сколько подписок может быть у одного соединения
+	WriteWait        = 10 * time.Second // Сколько ждать отправки одного кадра
+	PongWait         = 60 * time.Second // Сколько ждать pong от клиента
+	PingPeriod       = 30 * time.Second // Как часто отправлять ping (должно быть меньше PongWait)
+)
+
+type Subscriber interface {
+	Connect() *hub.Subscription
+	Watch(sub *hub.Subscription, channel, coin, quote string)
+	Unwatch(sub *hub.Subscription, channel, coin, quote string)
+	Unsubscribe(sub *hub.Subscription)
+}
+
+// ClientMessage - сообщение клиента
+type ClientMessage struct {
+	Action  string `json:"action"`  // subscribe | unsubscribe
+	Channel string `json:"channel"` // prices | alerts
+	Coin    string `json:"coin"`
+	Quote   string `json:"quote"` // По умолчанию USD
+}
+
+// ServerMessage - кадр, отправляемый клиенту
+type ServerMessage struct {
+	Type    string `json:"type"` // price | alert | subscribed | unsubscribed | error
+	Channel string `json:"channel,omitempty"`
+	Coin    string `json:"coin,omitempty"`
+	Quote   string `json:"quote,omitempty"`
+	Data    any    `json:"data,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type topic struct {
+	channel string
+	coin    string
+	quote   string
+}
+
+var upgrader = websocket.Upgrader{
+	// API не использует cookie и авторизацию, поэтому подключения разрешены с любых origin
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// @Summary Подписка на цены и оповещения (WebSocket)
+// @Description Клиент отправляет {"action":"subscribe|unsubscribe","channel":"prices|alerts","coin":"Bitcoin","quote":"USD"}.
+// @Description Подписка относится к валюте в котировке quote (по умолчанию USD): цены и оповещения в других котировках не приходят.
+// @Description Сервер отправляет кадры {"type":"price|alert","coin":...,"quote":...,"data":...}, подтверждения subscribed/unsubscribed и error.
+// @Description Клиент, не успевающий читать кадры, отключается с кодом 1013.
+// @ID ws
+// @Success 101 "Switching Protocols"
+// @Router /ws [get]
+func New(log *slog.Logger, subscriber Subscriber) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Error("Failed to upgrade connection", "error", err)
+			return
+		}
+		defer conn.Close()
+
+		sub := subscriber.Connect()
+		defer subscriber.Unsubscribe(sub)
+
+		// Ответы на сообщения клиента отправляет только пишущая горутина
+		replies := make(chan ServerMessage, MaxSubscriptions)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			readLoop(log, conn, subscriber, sub, replies)
+		}()
+
+		writeLoop(log, conn, sub, replies, done)
+	}
+}
+
+// readLoop обрабатывает сообщения клиента до закрытия соединения
+func readLoop(log *slog.Logger, conn *websocket.Conn, subscriber Subscriber, sub *hub.Subscription,
+	replies chan<- ServerMessage) {
+	conn.SetReadLimit(4096)
+	conn.SetReadDeadline(time.Now().Add(PongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(PongWait))
+	})
+
+	topics := make(map[topic]bool)
+	for {
+		var msg ClientMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Warn("Failed to read websocket message", "error", err)
+			}
+			return
+		}
+
+		reply := handleMessage(subscriber, sub, topics, msg)
+		select {
+		case replies <- reply:
+		default:
+			// Клиент шлёт сообщения быстрее, чем читает ответы
+			return
+		}
+	}
+}
+
+func handleMessage(subscriber Subscriber, sub *hub.Subscription, topics map[topic]bool, msg ClientMessage) ServerMessage {
+	msg.Coin = strings.TrimSpace(msg.Coin)
+	msg.Quote = models.NormalizeQuote(msg.Quote)
+	if msg.Channel != hub.ChannelPrices && msg.Channel != hub.ChannelAlerts {
+		return ServerMessage{Type: "error", Error: "Unknown channel"}
+	}
+	if msg.Coin == "" {
+		return ServerMessage{Type: "error", Channel: msg.Channel, Error: "Coin field is required"}
+	}
+
+	t := topic{channel: msg.Channel, coin: msg.Coin, quote: msg.Quote}
+	switch msg.Action {
+	case "subscribe":
+		if !topics[t] && len(topics) >= MaxSubscriptions {
+			return ServerMessage{Type: "error", Channel: msg.Channel, Coin: msg.Coin, Quote: msg.Quote, Error: "Subscription limit reached"}
+		}
+		topics[t] = true
+		subscriber.Watch(sub, msg.Channel, msg.Coin, msg.Quote)
+		return ServerMessage{Type: "subscribed", Channel: msg.Channel, Coin: msg.Coin, Quote: msg.Quote}
+	case "unsubscribe":
+		delete(topics, t)
+		subscriber.Unwatch(sub, msg.Channel, msg.Coin, msg.Quote)
+		return ServerMessage{Type: "unsubscribed", Channel: msg.Channel, Coin: msg.Coin, Quote: msg.Quote}
+	default:
+		return ServerMessage{Type: "error", Channel: msg.Channel, Coin: msg.Coin, Quote: msg.Quote, Error: "Unknown action"}
+	}
+}
+
+// writeLoop отправляет клиенту цены, оповещения и ответы до закрытия соединения
+func writeLoop(log *slog.Logger, conn *websocket.Conn, sub *hub.Subscription, replies <-chan ServerMessage,
+	done <-chan struct{}) {
+	ping := time.NewTicker(PingPeriod)
+	defer ping.Stop()
+
+	for {
+		var msg ServerMessage
+		select {
+		case <-done:
+			return
+		case reply := <-replies:
+			msg = reply
+		case coin, ok := <-sub.Prices():
+			if !ok {
+				slowConsumer(log, conn)
+				return
+			}
+			msg = ServerMessage{Type: "price", Channel: hub.ChannelPrices, Coin: coin.Name, Quote: coin.Quote, Data: coin}
+		case notification, ok := <-sub.Alerts():
+			if !ok {
+				slowConsumer(log, conn)
+				return
+			}
+			msg = ServerMessage{Type: "alert", Channel: hub.ChannelAlerts, Coin: notification.Coin, Quote: notification.Quote, Data: notification}
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(WriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+			continue
+		}
+
+		conn.SetWriteDeadline(time.Now().Add(WriteWait))
+		if err := conn.WriteJSON(msg); err != nil {
+			log.Warn("Failed to write websocket message", "error", err)
+			return
+		}
+	}
+}
+
+// slowConsumer закрывает соединение клиента, отключённого хабом
+func slowConsumer(log *slog.Logger, conn *websocket.Conn) {
+	log.Warn("Websocket subscriber dropped as slow consumer", "remote", conn.RemoteAddr().String())
+	conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "slow consumer"),
+		time.Now().Add(WriteWait))
+}
@@ -0,0 +1,171 @@
+package hub
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"sync"
+)
+
+// Каналы, на которые можно подписаться
+const (
+	ChannelPrices = "prices"
+	ChannelAlerts = "alerts"
+)
+
+// Hub - внутрипроцессный pub/sub для новых цен и сработавших оповещений.
+// Публикация никогда не блокирует коллектор: подписчик, не успевающий читать, отключается.
+type Hub struct {
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	buffer int
+}
+
+// pair - валюта в котировке: цены одной валюты в разных котировках - разные ряды
+type pair struct {
+	coin  string
+	quote string
+}
+
+// Subscription - подписка на цены и оповещения выбранных валют
+type Subscription struct {
+	prices     chan models.Coin
+	alerts     chan models.AlertNotification
+	allPrices  bool          // Цены всех валют в котировке allQuote
+	allQuote   string        // Котировка подписки на все валюты
+	coins      map[pair]bool // Валюты, цены которых нужны подписчику
+	alertCoins map[pair]bool // Валюты, оповещения по которым нужны подписчику
+}
+
+func New(buffer int) *Hub {
+	return &Hub{
+		subs:   make(map[*Subscription]struct{}),
+		buffer: buffer,
+	}
+}
+
+// Subscribe создаёт подписку на цены валют coins в котировке quote (пустой список - все валюты в этой котировке)
+func (h *Hub) Subscribe(coins []string, quote string) *Subscription {
+	sub := h.newSubscription()
+	sub.allPrices = len(coins) == 0
+	sub.allQuote = quote
+	for _, coin := range coins {
+		sub.coins[pair{coin: coin, quote: quote}] = true
+	}
+
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+
+	return sub
+}
+
+// Connect создаёт пустую подписку, каналы которой добавляются через Watch
+func (h *Hub) Connect() *Subscription {
+	sub := h.newSubscription()
+
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+
+	return sub
+}
+
+// Watch добавляет в подписку канал channel по валюте coin в котировке quote
+func (h *Hub) Watch(sub *Subscription, channel, coin, quote string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch channel {
+	case ChannelPrices:
+		sub.coins[pair{coin: coin, quote: quote}] = true
+	case ChannelAlerts:
+		sub.alertCoins[pair{coin: coin, quote: quote}] = true
+	}
+}
+
+// Unwatch удаляет из подписки канал channel по валюте coin в котировке quote
+func (h *Hub) Unwatch(sub *Subscription, channel, coin, quote string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch channel {
+	case ChannelPrices:
+		delete(sub.coins, pair{coin: coin, quote: quote})
+	case ChannelAlerts:
+		delete(sub.alertCoins, pair{coin: coin, quote: quote})
+	}
+}
+
+// Unsubscribe отменяет подписку. Повторный вызов безопасен.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.drop(sub)
+}
+
+// OnPrice публикует цену всем подписчикам (реализует tracker.Observer)
+func (h *Hub) OnPrice(_ context.Context, coin models.Coin) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs {
+		if sub.allPrices && coin.Quote != sub.allQuote ||
+			!sub.allPrices && !sub.coins[pair{coin: coin.Name, quote: coin.Quote}] {
+			continue
+		}
+		select {
+		case sub.prices <- coin:
+		default:
+			// Медленный подписчик: отключаем, чтобы не задерживать остальных
+			h.drop(sub)
+		}
+	}
+}
+
+// Notify публикует сработавшее оповещение всем подписчикам (реализует alerts.Notifier)
+func (h *Hub) Notify(_ context.Context, notification models.AlertNotification) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs {
+		if !sub.alertCoins[pair{coin: notification.Coin, quote: notification.Quote}] {
+			continue
+		}
+		select {
+		case sub.alerts <- notification:
+		default:
+			h.drop(sub)
+		}
+	}
+	return nil
+}
+
+func (h *Hub) newSubscription() *Subscription {
+	return &Subscription{
+		prices:     make(chan models.Coin, h.buffer),
+		alerts:     make(chan models.AlertNotification, h.buffer),
+		coins:      make(map[pair]bool),
+		alertCoins: make(map[pair]bool),
+	}
+}
+
+// drop удаляет подписку и закрывает её каналы. Вызывается под h.mu.
+func (h *Hub) drop(sub *Subscription) {
+	if _, ok := h.subs[sub]; !ok {
+		return
+	}
+	delete(h.subs, sub)
+	close(sub.prices)
+	close(sub.alerts)
+}
+
+// Prices возвращает канал цен. Канал закрывается при отписке или отключении медленного подписчика.
+func (s *Subscription) Prices() <-chan models.Coin {
+	return s.prices
+}
+
+// Alerts возвращает канал оповещений. Закрывается вместе с каналом цен.
+func (s *Subscription) Alerts() <-chan models.AlertNotification {
+	return s.alerts
+}
@@ -0,0 +1,148 @@
+package index
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// BaseLevel - уровень индекса в базовый момент
+const BaseLevel = 100.0
+
+// Расписания ребалансировки
+const (
+	RebalanceNone    = "none"
+	RebalanceDaily   = "daily"
+	RebalanceWeekly  = "weekly"
+	RebalanceMonthly = "monthly"
+)
+
+// MaxSamples - максимальное число моментов (точки ряда и ребалансировки), для которых запрашиваются цены
+var MaxSamples = 10000
+
+var ErrTooManySamples = errors.New("too many samples")
+
+type PriceStorage interface {
+	GetPricesAt(ctx context.Context, coins []string, quote string, at []int64) ([]models.SampledPrice, error)
+}
+
+// Service считает уровень индекса корзины по ценам из истории
+type Service struct {
+	storage PriceStorage
+}
+
+func New(storage PriceStorage) *Service {
+	return &Service{storage: storage}
+}
+
+// History возвращает уровень индекса в моменты from, from+step, ..., to (не раньше базового момента).
+// В базовый момент индекс равен BaseLevel, а количество каждой валюты выбирается по её весу;
+// при ребалансировке количества пересчитываются по текущему уровню и исходным весам.
+// Если у какой-либо валюты нет цены на базовый момент, возвращается storage.ErrPriceNotFound.
+func (s *Service) History(ctx context.Context, basket models.Basket, from, to, step int64) ([]models.IndexPoint, error) {
+	const op = "index.History"
+
+	from = max(from, basket.BaseTime)
+	if from > to {
+		return make([]models.IndexPoint, 0), nil
+	}
+
+	points := make(map[int64]bool)
+	for t := from; t <= to; t += step {
+		points[t] = true
+	}
+	rebalances := make(map[int64]bool)
+	for _, t := range RebalanceTimes(basket.BaseTime, to, basket.Rebalance) {
+		rebalances[t] = true
+	}
+
+	times := []int64{basket.BaseTime}
+	for t := range points {
+		if t != basket.BaseTime {
+			times = append(times, t)
+		}
+	}
+	for t := range rebalances {
+		if t != basket.BaseTime && !points[t] {
+			times = append(times, t)
+		}
+	}
+	if len(times) > MaxSamples {
+		return nil, fmt.Errorf("%s; %w: %d > %d", op, ErrTooManySamples, len(times), MaxSamples)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	coins := make([]string, 0, len(basket.Components))
+	for _, component := range basket.Components {
+		coins = append(coins, component.Coin)
+	}
+	samples, err := s.storage.GetPricesAt(ctx, coins, basket.Quote, times)
+	if err != nil {
+		return nil, fmt.Errorf("%s; %w", op, err)
+	}
+	prices := make(map[int64]map[string]float64, len(times))
+	for _, sample := range samples {
+		if sample.Price == nil {
+			continue
+		}
+		if prices[sample.At] == nil {
+			prices[sample.At] = make(map[string]float64, len(coins))
+		}
+		prices[sample.At][sample.Coin] = *sample.Price
+	}
+
+	units := make(map[string]float64, len(coins))
+	result := make([]models.IndexPoint, 0, len(points))
+	for _, t := range times {
+		for _, coin := range coins {
+			if prices[t][coin] <= 0 {
+				return nil, fmt.Errorf("%s; %s at %d: %w", op, coin, t, storage.ErrPriceNotFound)
+			}
+		}
+
+		level := BaseLevel
+		if t != basket.BaseTime {
+			level = 0
+			for _, coin := range coins {
+				level += units[coin] * prices[t][coin]
+			}
+		}
+		if t == basket.BaseTime || rebalances[t] {
+			for _, component := range basket.Components {
+				units[component.Coin] = level * component.Weight / prices[t][component.Coin]
+			}
+		}
+		if points[t] {
+			result = append(result, models.IndexPoint{Timestamp: t, Level: level})
+		}
+	}
+	return result, nil
+}
+
+// RebalanceTimes возвращает моменты ребалансировки после base и не позже to.
+// Ежемесячная ребалансировка идёт по календарным месяцам в UTC.
+func RebalanceTimes(base, to int64, schedule string) []int64 {
+	times := make([]int64, 0)
+	start := time.UnixMilli(base).UTC()
+	for k := 1; ; k++ {
+		var next time.Time
+		switch schedule {
+		case RebalanceDaily:
+			next = start.AddDate(0, 0, k)
+		case RebalanceWeekly:
+			next = start.AddDate(0, 0, 7*k)
+		case RebalanceMonthly:
+			next = start.AddDate(0, k, 0)
+		default:
+			return times
+		}
+		if next.UnixMilli() > to {
+			return times
+		}
+		times = append(times, next.UnixMilli())
+	}
+}
@@ -0,0 +1,128 @@
+package ledger
+
+import (
+	"crypto_tracker/internal/models"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Типы транзакций
+const (
+	TypeBuy         = "buy"
+	TypeSell        = "sell"
+	TypeTransferIn  = "transfer_in"  // Поступление без покупки: себестоимость по рыночной цене
+	TypeTransferOut = "transfer_out" // Вывод без продажи: списывает лоты без реализованной прибыли
+)
+
+// Методы расчёта себестоимости
+const (
+	MethodFIFO    = "fifo"
+	MethodLIFO    = "lifo"
+	MethodAverage = "average"
+)
+
+const epsilon = 1e-12 // Остаток количества, который считается нулём
+
+var ErrInsufficientQuantity = errors.New("insufficient quantity")
+
+type lot struct {
+	quantity float64
+	cost     float64 // Себестоимость единицы с учётом комиссии
+}
+
+// Position - позиция по валюте после применения транзакций
+type Position struct {
+	Coin      string
+	Quantity  float64
+	CostBasis float64
+	Realised  float64
+}
+
+// Positions восстанавливает позиции по транзакциям (в хронологическом порядке) методом method.
+// Возвращает ErrInsufficientQuantity, если продаётся или выводится больше, чем есть.
+func Positions(txs []models.Transaction, method string) ([]Position, error) {
+	lots := make(map[string][]lot)
+	realised := make(map[string]float64)
+
+	for _, tx := range txs {
+		switch tx.Type {
+		case TypeBuy, TypeTransferIn:
+			lots[tx.Coin] = add(lots[tx.Coin], lot{
+				quantity: tx.Quantity,
+				cost:     (tx.Quantity*tx.Price + tx.Fee) / tx.Quantity,
+			}, method)
+		case TypeSell, TypeTransferOut:
+			remaining, cost, err := take(lots[tx.Coin], tx.Quantity, method)
+			if err != nil {
+				return nil, fmt.Errorf("transaction %d (%s %s): %w", tx.ID, tx.Type, tx.Coin, err)
+			}
+			lots[tx.Coin] = remaining
+			if tx.Type == TypeSell {
+				realised[tx.Coin] += tx.Quantity*tx.Price - tx.Fee - cost
+			} else {
+				realised[tx.Coin] -= tx.Fee
+			}
+		default:
+			return nil, fmt.Errorf("transaction %d: unknown type %q", tx.ID, tx.Type)
+		}
+		if _, ok := realised[tx.Coin]; !ok {
+			realised[tx.Coin] = 0
+		}
+	}
+
+	positions := make([]Position, 0, len(realised))
+	for coin, pnl := range realised {
+		position := Position{Coin: coin, Realised: pnl}
+		for _, l := range lots[coin] {
+			position.Quantity += l.quantity
+			position.CostBasis += l.quantity * l.cost
+		}
+		positions = append(positions, position)
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i].Coin < positions[j].Coin })
+	return positions, nil
+}
+
+// add добавляет лот. При методе average все лоты сливаются в один со средней себестоимостью.
+func add(lots []lot, l lot, method string) []lot {
+	if method == MethodAverage && len(lots) > 0 {
+		total := lots[0].quantity + l.quantity
+		return []lot{{
+			quantity: total,
+			cost:     (lots[0].quantity*lots[0].cost + l.quantity*l.cost) / total,
+		}}
+	}
+	return append(lots, l)
+}
+
+// take списывает quantity из лотов и возвращает оставшиеся лоты и себестоимость списанного.
+// FIFO списывает с самых старых лотов, LIFO - с самых новых.
+func take(lots []lot, quantity float64, method string) ([]lot, float64, error) {
+	var held float64
+	for _, l := range lots {
+		held += l.quantity
+	}
+	if quantity > held+epsilon {
+		return nil, 0, fmt.Errorf("%w: have %g, need %g", ErrInsufficientQuantity, held, quantity)
+	}
+
+	remaining := append([]lot(nil), lots...)
+	var cost float64
+	for quantity > epsilon && len(remaining) > 0 {
+		i := 0
+		if method == MethodLIFO {
+			i = len(remaining) - 1
+		}
+
+		taken := min(quantity, remaining[i].quantity)
+		cost += taken * remaining[i].cost
+		quantity -= taken
+		remaining[i].quantity -= taken
+
+		if remaining[i].quantity <= epsilon {
+			remaining = append(remaining[:i], remaining[i+1:]...)
+		}
+	}
+	return remaining, cost, nil
+}
@@ -0,0 +1,132 @@
+package ledger
+
+import (
+	"crypto_tracker/internal/models"
+	"errors"
+	"math"
+	"testing"
+)
+
+func buy(coin string, quantity, price, fee float64) models.Transaction {
+	return models.Transaction{Coin: coin, Type: TypeBuy, Quantity: quantity, Price: price, Fee: fee}
+}
+
+func sell(coin string, quantity, price, fee float64) models.Transaction {
+	return models.Transaction{Coin: coin, Type: TypeSell, Quantity: quantity, Price: price, Fee: fee}
+}
+
+func TestPositions(t *testing.T) {
+	// Покупки 1 по 100 и 1 по 200, продажа 1 по 300
+	lots := []models.Transaction{
+		buy("Bitcoin", 1, 100, 0),
+		buy("Bitcoin", 1, 200, 0),
+		sell("Bitcoin", 1, 300, 0),
+	}
+
+	tests := []struct {
+		name   string
+		txs    []models.Transaction
+		method string
+		want   []Position
+	}{
+		{"fifo sells oldest lot", lots, MethodFIFO, []Position{{"Bitcoin", 1, 200, 200}}},
+		{"lifo sells newest lot", lots, MethodLIFO, []Position{{"Bitcoin", 1, 100, 100}}},
+		{"average cost", lots, MethodAverage, []Position{{"Bitcoin", 1, 150, 150}}},
+		{
+			"fifo across lots",
+			[]models.Transaction{buy("Bitcoin", 2, 100, 0), buy("Bitcoin", 2, 200, 0), sell("Bitcoin", 3, 300, 0)},
+			MethodFIFO,
+			// Себестоимость проданного 2*100 + 1*200
+			[]Position{{"Bitcoin", 1, 200, 900 - 400}},
+		},
+		{
+			"lifo across lots",
+			[]models.Transaction{buy("Bitcoin", 2, 100, 0), buy("Bitcoin", 2, 200, 0), sell("Bitcoin", 3, 300, 0)},
+			MethodLIFO,
+			// Себестоимость проданного 2*200 + 1*100
+			[]Position{{"Bitcoin", 1, 100, 900 - 500}},
+		},
+		{
+			"average after partial sale",
+			[]models.Transaction{buy("Bitcoin", 1, 100, 0), sell("Bitcoin", 0.5, 200, 0), buy("Bitcoin", 1.5, 300, 0)},
+			MethodAverage,
+			// Остаток 0.5 по 100 сливается с 1.5 по 300: средняя 250
+			[]Position{{"Bitcoin", 2, 500, 50}},
+		},
+		{
+			"fees in cost and proceeds",
+			[]models.Transaction{buy("Bitcoin", 2, 100, 10), sell("Bitcoin", 1, 150, 5)},
+			MethodFIFO,
+			// Себестоимость единицы (200 + 10) / 2 = 105, выручка 150 - 5
+			[]Position{{"Bitcoin", 1, 105, 145 - 105}},
+		},
+		{
+			"transfers",
+			[]models.Transaction{
+				{Coin: "Bitcoin", Type: TypeTransferIn, Quantity: 2, Price: 100},
+				{Coin: "Bitcoin", Type: TypeTransferOut, Quantity: 1, Fee: 3},
+			},
+			MethodFIFO,
+			[]Position{{"Bitcoin", 1, 100, -3}},
+		},
+		{
+			"closed position and several coins",
+			[]models.Transaction{
+				buy("Ethereum", 1, 10, 0), buy("Bitcoin", 1, 100, 0), sell("Bitcoin", 1, 120, 0),
+			},
+			MethodFIFO,
+			[]Position{{"Bitcoin", 0, 0, 20}, {"Ethereum", 1, 10, 0}},
+		},
+		{"empty", nil, MethodFIFO, []Position{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Positions(tt.txs, tt.method)
+			if err != nil {
+				t.Fatalf("Positions() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Positions() = %+v, want %+v", got, tt.want)
+			}
+			for i, want := range tt.want {
+				if got[i].Coin != want.Coin || !near(got[i].Quantity, want.Quantity) ||
+					!near(got[i].CostBasis, want.CostBasis) || !near(got[i].Realised, want.Realised) {
+					t.Errorf("position %d = %+v, want %+v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestPositionsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		txs     []models.Transaction
+		wantErr error
+	}{
+		{"sell more than held", []models.Transaction{buy("Bitcoin", 1, 100, 0), sell("Bitcoin", 2, 100, 0)}, ErrInsufficientQuantity},
+		{"sell before buy", []models.Transaction{sell("Bitcoin", 1, 100, 0), buy("Bitcoin", 1, 100, 0)}, ErrInsufficientQuantity},
+		{"transfer out of another coin", []models.Transaction{
+			buy("Bitcoin", 1, 100, 0),
+			{Coin: "Ethereum", Type: TypeTransferOut, Quantity: 1},
+		}, ErrInsufficientQuantity},
+		{"unknown type", []models.Transaction{{Coin: "Bitcoin", Type: "swap", Quantity: 1}}, nil},
+	}
+	for _, tt := range tests {
+		for _, method := range []string{MethodFIFO, MethodLIFO, MethodAverage} {
+			t.Run(tt.name+"/"+method, func(t *testing.T) {
+				_, err := Positions(tt.txs, method)
+				if err == nil {
+					t.Fatal("Positions() error = nil")
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("Positions() error = %v, want %v", err, tt.wantErr)
+				}
+			})
+		}
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -0,0 +1,153 @@
+package ledger
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Storage хранит журнал транзакций. CreateTransaction и DeleteTransaction вызывают check с журналом портфеля
+// и изменяют его, только если check не вернул ошибку; чтение, проверка и изменение атомарны.
+type Storage interface {
+	CreateTransaction(ctx context.Context, tx models.Transaction, check func([]models.Transaction) error) (int64, error)
+	GetTransactions(ctx context.Context, portfolioID, until int64) ([]models.Transaction, error)
+	DeleteTransaction(ctx context.Context, portfolioID, id int64, check func([]models.Transaction) error) error
+	GetNearestPrice(ctx context.Context, coin, quote string, timestamp, tolerance int64) (models.Coin, error)
+	GetPriceBefore(ctx context.Context, coin, quote string, timestamp, tolerance int64) (models.Coin, error)
+}
+
+// Service ведёт журнал транзакций портфеля и считает по нему прибыль и убыток.
+// Все цены и суммы - в USD.
+type Service struct {
+	storage Storage
+}
+
+func New(storage Storage) *Service {
+	return &Service{storage: storage}
+}
+
+// Record сохраняет транзакцию. Если цена не указана, берётся ближайшая к времени транзакции цена
+// из истории не дальше tolerance, иначе возвращается storage.ErrPriceNotFound.
+// Транзакция, после которой количество валюты где-либо в журнале станет отрицательным,
+// отклоняется с ErrInsufficientQuantity.
+func (s *Service) Record(ctx context.Context, portfolioID int64, req models.TransactionRequest, tolerance time.Duration) (models.Transaction, error) {
+	const op = "ledger.Record"
+
+	tx := models.Transaction{
+		PortfolioID: portfolioID,
+		Coin:        strings.TrimSpace(req.Coin),
+		Type:        req.Type,
+		Quantity:    req.Quantity,
+		Fee:         req.Fee,
+		Timestamp:   req.Timestamp,
+		CreatedAt:   time.Now().UnixMilli(),
+	}
+	if req.Price != nil {
+		tx.Price = *req.Price
+	} else {
+		price, err := s.storage.GetNearestPrice(ctx, tx.Coin, models.DefaultQuote, tx.Timestamp, tolerance.Milliseconds())
+		if err != nil {
+			return models.Transaction{}, fmt.Errorf("%s; price of %s at %d: %w", op, tx.Coin, tx.Timestamp, err)
+		}
+		tx.Price = price.Price
+		tx.PriceTime = &price.Timestamp
+	}
+
+	id, err := s.storage.CreateTransaction(ctx, tx, func(txs []models.Transaction) error {
+		// Новая транзакция встаёт после уже записанных с тем же временем
+		i := sort.Search(len(txs), func(i int) bool { return txs[i].Timestamp > tx.Timestamp })
+		txs = append(txs[:i], append([]models.Transaction{tx}, txs[i:]...)...)
+		_, err := Positions(txs, MethodFIFO)
+		return err
+	})
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("%s; %w", op, err)
+	}
+	tx.ID = id
+	return tx, nil
+}
+
+// Delete удаляет транзакцию, если без неё журнал остаётся согласованным
+func (s *Service) Delete(ctx context.Context, portfolioID, id int64) error {
+	const op = "ledger.Delete"
+
+	err := s.storage.DeleteTransaction(ctx, portfolioID, id, func(txs []models.Transaction) error {
+		rest := make([]models.Transaction, 0, len(txs))
+		for _, tx := range txs {
+			if tx.ID != id {
+				rest = append(rest, tx)
+			}
+		}
+		if len(rest) == len(txs) {
+			return nil
+		}
+		_, err := Positions(rest, MethodFIFO)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s; %w", op, err)
+	}
+	return nil
+}
+
+func (s *Service) Transactions(ctx context.Context, portfolioID int64) ([]models.Transaction, error) {
+	const op = "ledger.Transactions"
+
+	txs, err := s.storage.GetTransactions(ctx, portfolioID, math.MaxInt64)
+	if err != nil {
+		return nil, fmt.Errorf("%s; %w", op, err)
+	}
+	return txs, nil
+}
+
+// PnL считает реализованную и нереализованную прибыль портфеля в момент at по транзакциям не позже at.
+// Открытые позиции оцениваются по последней цене из истории не позже at и не раньше at - tolerance;
+// если такой цены нет, возвращается storage.ErrPriceNotFound.
+func (s *Service) PnL(ctx context.Context, portfolioID, at int64, method string, tolerance time.Duration) (models.PortfolioPnL, error) {
+	const op = "ledger.PnL"
+
+	txs, err := s.storage.GetTransactions(ctx, portfolioID, at)
+	if err != nil {
+		return models.PortfolioPnL{}, fmt.Errorf("%s; %w", op, err)
+	}
+	positions, err := Positions(txs, method)
+	if err != nil {
+		return models.PortfolioPnL{}, fmt.Errorf("%s; %w", op, err)
+	}
+
+	pnl := models.PortfolioPnL{
+		PortfolioID: portfolioID,
+		Method:      method,
+		At:          at,
+		Quote:       models.DefaultQuote,
+		Positions:   make([]models.PositionPnL, 0, len(positions)),
+	}
+	for _, position := range positions {
+		result := models.PositionPnL{
+			Coin:        position.Coin,
+			Quantity:    position.Quantity,
+			CostBasis:   position.CostBasis,
+			RealisedPnL: position.Realised,
+		}
+		if position.Quantity > epsilon {
+			price, err := s.storage.GetPriceBefore(ctx, position.Coin, models.DefaultQuote, at, tolerance.Milliseconds())
+			if err != nil {
+				return models.PortfolioPnL{}, fmt.Errorf("%s; price of %s at %d: %w", op, position.Coin, at, err)
+			}
+			result.AverageCost = position.CostBasis / position.Quantity
+			result.MarketPrice = price.Price
+			result.MarketTimestamp = price.Timestamp
+			result.MarketValue = position.Quantity * price.Price
+			result.UnrealisedPnL = result.MarketValue - position.CostBasis
+		}
+
+		pnl.RealisedPnL += result.RealisedPnL
+		pnl.UnrealisedPnL += result.UnrealisedPnL
+		pnl.Positions = append(pnl.Positions, result)
+	}
+	return pnl, nil
+}
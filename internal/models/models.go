@@ -1,16 +1,586 @@
 package models
 
+import (
+	"regexp"
+	"strings"
+)
+
+const DefaultQuote = "USD" // Валюта котировки по умолчанию
+
+// quotePattern - код валюты или актива котировки (USD, EUR, BTC, USDT, ...), не длиннее колонки quote (varchar(16))
+var quotePattern = regexp.MustCompile(`^[A-Z0-9]{2,16}$`)
+
 type Coin struct {
-	Name      string  `json:"coin"`
-	Price     float64 `json:"price"`
-	Timestamp int64   `json:"timestamp"`
+	ID         int64       `json:"-"` // id_coin, заполняется после сохранения
+	Name       string      `json:"coin"`
+	Quote      string      `json:"quote"`
+	Price      float64     `json:"price"`
+	Timestamp  int64       `json:"timestamp"`
+	Provenance *Provenance `json:"provenance,omitempty"` // Возвращается только по запросу
+}
+
+// Provenance - откуда и когда получена цена. У цен, сохранённых до появления этих сведений, отсутствует.
+type Provenance struct {
+	Provider     string   `json:"provider"`
+	ProviderTime *float64 `json:"provider_time"` // Время цены в том виде, в каком его вернул провайдер
+	IngestedAt   *int64   `json:"ingested_at"`   // Когда коллектор получил цену, null для загруженной истории
+	Latency      *int64   `json:"latency"`       // ingested_at - timestamp в миллисекундах
 }
 
+// Как цена попала в coins (колонка source)
+const (
+	SourceLive     = "live"     // Собрана коллектором и отправлена подписчикам
+	SourceBackfill = "backfill" // Загружена задачей загрузки истории
+	SourceGapFill  = "gap_fill" // Дозаполнение пропуска
+)
+
 type CoinRequest struct {
-	Coin string `json:"coin"`
+	Coin         string `json:"coin"`
+	Quote        string `json:"quote"`                   // USD, EUR, BTC, ... (по умолчанию USD)
+	BackfillFrom *int64 `json:"backfill_from,omitempty"` // Загрузить историю цен начиная с этого времени (мс)
+	AllowPending bool   `json:"allow_pending"`           // Добавить без проверки, если провайдер недоступен
+}
+
+type AddCoinResponse struct {
+	Message string `json:"message"`
+	State   string `json:"state"`            // running или pending_validation
+	JobID   *int64 `json:"job_id,omitempty"` // Задача загрузки истории, если передан backfill_from
+	// Если задачу загрузки истории создать не удалось: валюта всё равно отслеживается, загрузку можно запросить повторно
+	BackfillError string `json:"backfill_error,omitempty"`
 }
 
 type GetPriceRequest struct {
-	Coin      string `json:"coin" validate:"required"`
-	Timestamp string `json:"timestamp" validate:"required"`
+	Coin       string `json:"coin" validate:"required"`
+	Quote      string `json:"quote"`
+	Convert    string `json:"convert"` // Фиатная валюта, в которую пересчитать цену (например EUR)
+	Timestamp  string `json:"timestamp" validate:"required"`
+	Provenance bool   `json:"provenance"` // Вернуть источник и время получения цены
+}
+
+// NormalizeQuote приводит код валюты котировки к верхнему регистру, пустой код - к USD
+func NormalizeQuote(quote string) string {
+	quote = strings.ToUpper(strings.TrimSpace(quote))
+	if quote == "" {
+		return DefaultQuote
+	}
+	return quote
+}
+
+// ValidQuote проверяет код валюты котировки, приведённый NormalizeQuote
+func ValidQuote(quote string) bool {
+	return quotePattern.MatchString(quote)
+}
+
+type Alert struct {
+	ID                int64    `json:"id"`
+	Coin              string   `json:"coin"`
+	Quote             string   `json:"quote"`
+	Condition         string   `json:"condition"`
+	Price             *float64 `json:"price,omitempty"`
+	ChangePercent     *float64 `json:"change_percent,omitempty"`
+	Window            string   `json:"window,omitempty"`
+	Indicator         string   `json:"indicator,omitempty"`
+	IndicatorValue    *float64 `json:"indicator_value,omitempty"`
+	IndicatorPeriod   int      `json:"indicator_period,omitempty"`
+	IndicatorInterval string   `json:"indicator_interval,omitempty"`
+	HysteresisPercent float64  `json:"hysteresis_percent"`
+	Cooldown          string   `json:"cooldown,omitempty"`
+	State             string   `json:"state"`
+	LastNotifiedAt    *int64   `json:"last_notified_at,omitempty"`
+	CreatedAt         int64    `json:"created_at"`
+}
+
+type AlertRequest struct {
+	Coin              string   `json:"coin" validate:"required"`
+	Quote             string   `json:"quote"`
+	Condition         string   `json:"condition" validate:"required,oneof=above below"`
+	Price             *float64 `json:"price" validate:"omitempty,gt=0"`
+	ChangePercent     *float64 `json:"change_percent" validate:"omitempty,gt=0"`
+	Window            string   `json:"window"`
+	Indicator         string   `json:"indicator" validate:"omitempty,oneof=sma ema rsi macd"`
+	IndicatorValue    *float64 `json:"indicator_value"`
+	IndicatorPeriod   int      `json:"indicator_period" validate:"gte=0,lte=1000"`
+	IndicatorInterval string   `json:"indicator_interval"`
+	HysteresisPercent float64  `json:"hysteresis_percent" validate:"gte=0,lt=100"`
+	Cooldown          string   `json:"cooldown"`
+}
+
+// AlertEvent - переход правила оповещения между состояниями
+type AlertEvent struct {
+	ID        int64   `json:"id"`
+	AlertID   int64   `json:"alert_id"`
+	FromState string  `json:"from_state"`
+	ToState   string  `json:"to_state"`
+	Price     float64 `json:"price"`
+	Timestamp int64   `json:"timestamp"`
+	Notified  bool    `json:"notified"`
+	CreatedAt int64   `json:"created_at"`
+}
+
+// AlertNotification - тело запроса, отправляемого на webhook при срабатывании правила
+type AlertNotification struct {
+	AlertID        int64    `json:"alert_id"`
+	State          string   `json:"state"`
+	Coin           string   `json:"coin"`
+	Quote          string   `json:"quote"`
+	Condition      string   `json:"condition"`
+	Threshold      *float64 `json:"threshold,omitempty"`
+	ChangePercent  *float64 `json:"change_percent,omitempty"`
+	Window         string   `json:"window,omitempty"`
+	Indicator      string   `json:"indicator,omitempty"`
+	IndicatorValue *float64 `json:"indicator_value,omitempty"`
+	Price          float64  `json:"price"`
+	Timestamp      int64    `json:"timestamp"`
+}
+
+// FXRate - курс фиатной валюты: сколько единиц Quote стоит одна единица Base
+type FXRate struct {
+	Base      string  `json:"base"`
+	Quote     string  `json:"quote"`
+	Rate      float64 `json:"rate"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+// CrossRate - курс Base/Quote, вычисленный по ценам обеих валют в общей котировке Via
+type CrossRate struct {
+	Base           string  `json:"base"`
+	Quote          string  `json:"quote"`
+	Via            string  `json:"via"`
+	Rate           float64 `json:"rate"`
+	Timestamp      int64   `json:"timestamp"`
+	BasePrice      float64 `json:"base_price"`
+	BaseTimestamp  int64   `json:"base_timestamp"`
+	QuotePrice     float64 `json:"quote_price"`
+	QuoteTimestamp int64   `json:"quote_timestamp"`
+}
+
+type CrossRateHistory struct {
+	Base      string      `json:"base"`
+	Quote     string      `json:"quote"`
+	Via       string      `json:"via"`
+	Tolerance string      `json:"tolerance"`
+	Points    []CrossRate `json:"points"`
+	Skipped   int         `json:"skipped"` // Цены базовой валюты без цены котируемой в пределах tolerance
+}
+
+// ConversionLeg - курс, использованный при конвертации, с временем выборки для аудита
+type ConversionLeg struct {
+	Type      string  `json:"type"` // price - цена из coins, fx - курс из fx_rates
+	Base      string  `json:"base"`
+	Quote     string  `json:"quote"`
+	Rate      float64 `json:"rate"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+type Conversion struct {
+	Amount float64         `json:"amount"`
+	From   string          `json:"from"`
+	To     string          `json:"to"`
+	At     int64           `json:"at"`
+	Rate   float64         `json:"rate"`
+	Result float64         `json:"result"`
+	Legs   []ConversionLeg `json:"legs"`
+}
+
+type Portfolio struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt int64     `json:"created_at"`
+	Holdings  []Holding `json:"holdings"`
+}
+
+type Holding struct {
+	Coin     string  `json:"coin"`
+	Quantity float64 `json:"quantity"`
+}
+
+type PortfolioRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type HoldingRequest struct {
+	Coin     string  `json:"coin" validate:"required"`
+	Quantity float64 `json:"quantity" validate:"gte=0"`
+	Track    bool    `json:"track"` // Добавить валюту в список отслеживаемых, если она ещё не отслеживается
+}
+
+// HoldingValue - стоимость позиции по последней цене не позже момента оценки
+type HoldingValue struct {
+	Coin      string  `json:"coin"`
+	Quantity  float64 `json:"quantity"`
+	Price     float64 `json:"price"`
+	Value     float64 `json:"value"`
+	Timestamp int64   `json:"timestamp"` // Время использованной цены
+}
+
+type PortfolioValue struct {
+	Timestamp int64          `json:"timestamp"`
+	Quote     string         `json:"quote"`
+	Total     float64        `json:"total"`
+	Holdings  []HoldingValue `json:"holdings,omitempty"`
+	Missing   []string       `json:"missing,omitempty"` // Позиции без цены на момент оценки (не входят в total)
+}
+
+type Transaction struct {
+	ID          int64   `json:"id"`
+	PortfolioID int64   `json:"portfolio_id"`
+	Coin        string  `json:"coin"`
+	Type        string  `json:"type"`
+	Quantity    float64 `json:"quantity"`
+	Price       float64 `json:"price"`                // Цена за единицу в USD
+	PriceTime   *int64  `json:"price_time,omitempty"` // Время цены из истории, если цена не была указана
+	Fee         float64 `json:"fee"`
+	Timestamp   int64   `json:"timestamp"`
+	CreatedAt   int64   `json:"created_at"`
+}
+
+type TransactionRequest struct {
+	Coin      string   `json:"coin" validate:"required"`
+	Type      string   `json:"type" validate:"required,oneof=buy sell transfer_in transfer_out"`
+	Quantity  float64  `json:"quantity" validate:"gt=0"`
+	Price     *float64 `json:"price" validate:"omitempty,gt=0"` // Если не указана, берётся из истории цен
+	Fee       float64  `json:"fee" validate:"gte=0"`
+	Timestamp int64    `json:"timestamp" validate:"required"`
+}
+
+// PositionPnL - позиция по одной валюте, восстановленная по транзакциям
+type PositionPnL struct {
+	Coin            string  `json:"coin"`
+	Quantity        float64 `json:"quantity"`
+	CostBasis       float64 `json:"cost_basis"`
+	AverageCost     float64 `json:"average_cost"`
+	RealisedPnL     float64 `json:"realised_pnl"`
+	MarketPrice     float64 `json:"market_price"`
+	MarketTimestamp int64   `json:"market_timestamp,omitempty"`
+	MarketValue     float64 `json:"market_value"`
+	UnrealisedPnL   float64 `json:"unrealised_pnl"`
+}
+
+type PortfolioPnL struct {
+	PortfolioID   int64         `json:"portfolio_id"`
+	Method        string        `json:"method"`
+	At            int64         `json:"at"`
+	Quote         string        `json:"quote"`
+	RealisedPnL   float64       `json:"realised_pnl"`
+	UnrealisedPnL float64       `json:"unrealised_pnl"`
+	Positions     []PositionPnL `json:"positions"`
+}
+
+// Basket - индекс из взвешенного набора валют
+type Basket struct {
+	ID         int64             `json:"id"`
+	Name       string            `json:"name"`
+	Quote      string            `json:"quote"`
+	BaseTime   int64             `json:"base_time"` // Момент, в который уровень индекса равен 100
+	Rebalance  string            `json:"rebalance"` // none, daily, weekly, monthly
+	Components []BasketComponent `json:"components"`
+	CreatedAt  int64             `json:"created_at"`
+}
+
+type BasketComponent struct {
+	Coin   string  `json:"coin" validate:"required"`
+	Weight float64 `json:"weight" validate:"gt=0"` // Доля в индексе; веса нормируются так, чтобы их сумма была 1
+}
+
+type BasketRequest struct {
+	Name       string            `json:"name" validate:"required"`
+	Quote      string            `json:"quote"`
+	BaseTime   int64             `json:"base_time" validate:"required"`
+	Rebalance  string            `json:"rebalance" validate:"omitempty,oneof=none daily weekly monthly"`
+	Components []BasketComponent `json:"components" validate:"required,min=1,dive"`
+}
+
+type IndexPoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Level     float64 `json:"level"`
+}
+
+// SampledPrice - последняя цена валюты не позже момента At
+type SampledPrice struct {
+	At        int64    `json:"at"`
+	Coin      string   `json:"coin"`
+	Price     *float64 `json:"price"`     // nil, если цены до At нет
+	Timestamp int64    `json:"timestamp"` // Время найденной цены
+}
+
+// CoinStats - статистика цен валюты за окно (from, to]
+type CoinStats struct {
+	Coin           string  `json:"coin"`
+	Quote          string  `json:"quote"`
+	Window         string  `json:"window"`
+	From           int64   `json:"from"`
+	To             int64   `json:"to"`
+	Samples        int64   `json:"samples"`
+	First          float64 `json:"first"`
+	FirstTimestamp int64   `json:"first_timestamp"`
+	Last           float64 `json:"last"`
+	LastTimestamp  int64   `json:"last_timestamp"`
+	Change         float64 `json:"change"`         // last - first
+	ChangePercent  float64 `json:"change_percent"` // (last - first) / first * 100
+	Min            float64 `json:"min"`
+	Max            float64 `json:"max"`
+	Mean           float64 `json:"mean"`
+	StdDev         float64 `json:"std_dev"`
+	ReturnsStdDev  float64 `json:"returns_std_dev"` // Стандартное отклонение логарифмических доходностей между соседними ценами
+	Volatility     float64 `json:"volatility"`      // Годовая волатильность логарифмических доходностей
+}
+
+// Candle - цены валюты за интервал [Timestamp, Timestamp + interval)
+type Candle struct {
+	Timestamp int64   `json:"timestamp"`
+	Open      float64 `json:"open"`
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	Close     float64 `json:"close"`
+	Samples   int64   `json:"samples"`
+}
+
+type IndicatorPoint struct {
+	Timestamp int64              `json:"timestamp"`
+	Close     float64            `json:"close"`
+	Values    map[string]float64 `json:"values"`
+}
+
+type IndicatorSeries struct {
+	Coin     string           `json:"coin"`
+	Quote    string           `json:"quote"`
+	Type     string           `json:"type"`
+	Interval string           `json:"interval"`
+	Points   []IndicatorPoint `json:"points"`
+}
+
+// CorrelationMatrix - корреляции доходностей валют на общей сетке интервалов.
+// Значения, для которых общих доходностей меньше двух, равны null.
+type CorrelationMatrix struct {
+	Coins       []string     `json:"coins"`
+	Quote       string       `json:"quote"`
+	Window      string       `json:"window"`
+	Interval    string       `json:"interval"`
+	From        int64        `json:"from"`
+	To          int64        `json:"to"`
+	Returns     []int        `json:"returns"`     // Число доходностей каждой валюты
+	Correlation [][]*float64 `json:"correlation"` // Корреляция доходностей coins[i] и coins[j]
+	Samples     [][]int      `json:"samples"`     // Число общих доходностей coins[i] и coins[j]
+	Benchmark   string       `json:"benchmark"`
+	Beta        []*float64   `json:"beta"`         // Бета coins[i] относительно benchmark
+	BetaSamples []int        `json:"beta_samples"` // Число общих доходностей coins[i] и benchmark
+}
+
+// Comparison - цены валют на общей сетке времени, приведённые к 100 в начальный момент
+type Comparison struct {
+	Quote      string             `json:"quote"`
+	Since      int64              `json:"since"`
+	To         int64              `json:"to"`
+	Step       string             `json:"step"`
+	Timestamps []int64            `json:"timestamps"`
+	Series     []ComparisonSeries `json:"series"`
+}
+
+type ComparisonSeries struct {
+	Coin          string     `json:"coin"`
+	BasePrice     *float64   `json:"base_price"`     // Цена, принятая за 100; null, если цен за период нет
+	BaseTimestamp int64      `json:"base_timestamp"` // Момент сетки, на который взята базовая цена
+	Change        *float64   `json:"change"`         // Изменение в процентах к последнему моменту сетки
+	Values        []*float64 `json:"values"`         // Значения в моменты timestamps; null, если значения нет
+}
+
+// LatestPrice - последняя сохранённая цена валюты и цены, предшествующие ей на заданные смещения
+type LatestPrice struct {
+	Coin
+	Previous []*float64 // Последние цены не позже Timestamp - offset для каждого смещения; nil, если цены нет
+}
+
+type SnapshotEntry struct {
+	Coin      string              `json:"coin"`
+	Quote     string              `json:"quote"`
+	Price     float64             `json:"price"`
+	Timestamp int64               `json:"timestamp"`
+	Changes   map[string]*float64 `json:"changes"` // Изменение в процентах за 1h, 24h, 7d; null, если истории не хватает
+}
+
+type Mover struct {
+	Coin           string  `json:"coin"`
+	Quote          string  `json:"quote"`
+	Price          float64 `json:"price"`
+	Timestamp      int64   `json:"timestamp"`
+	ReferencePrice float64 `json:"reference_price"` // Цена за window до timestamp
+	Change         float64 `json:"change"`          // Изменение в процентах
+}
+
+type Movers struct {
+	Window  string  `json:"window"`
+	Quote   string  `json:"quote"`
+	Gainers []Mover `json:"gainers"`
+	Losers  []Mover `json:"losers"`
+}
+
+// Способы заполнения интервалов сетки без цен
+const (
+	FillNone     = "none"     // Значение отсутствует
+	FillPrevious = "previous" // Последнее известное значение
+	FillLinear   = "linear"   // Линейная интерполяция между соседними известными значениями
+	FillZero     = "zero"     // Ноль
+)
+
+// Способы агрегации цен внутри интервала сетки
+const (
+	AggLast  = "last"
+	AggMean  = "mean"
+	AggFirst = "first"
+)
+
+// Resample - правила приведения цен к регулярной сетке from, from+step, ..., to.
+// Значение в момент t агрегируется из цен интервала (t - step, t].
+type Resample struct {
+	Step int64  `json:"step"` // Шаг сетки в миллисекундах
+	Fill string `json:"fill"`
+	Agg  string `json:"agg"`
+}
+
+type SeriesPoint struct {
+	Timestamp int64    `json:"timestamp"`
+	Value     *float64 `json:"value"`                // null, если в интервале нет цен и fill = none
+	Filled    bool     `json:"filled,omitempty"`     // Значение получено заполнением, а не из цен интервала
+	PriceTime int64    `json:"price_time,omitempty"` // Время последней цены, из которой получено значение (кроме linear и zero)
+}
+
+type PriceHistory struct {
+	Coin   string        `json:"coin"`
+	Quote  string        `json:"quote"`
+	From   int64         `json:"from"`
+	To     int64         `json:"to"`
+	Step   string        `json:"step"`
+	Fill   string        `json:"fill"`
+	Agg    string        `json:"agg"`
+	Points []SeriesPoint `json:"points"`
+}
+
+// Gap - промежуток между соседними сохранёнными ценами, превышающий допустимый
+type Gap struct {
+	From     int64 `json:"from"` // Время цены перед пропуском
+	To       int64 `json:"to"`   // Время цены после пропуска
+	Duration int64 `json:"duration"`
+}
+
+// GapScan - результат последней проверки пропусков валюты фоновой задачей
+type GapScan struct {
+	At        int64  `json:"at"`
+	Gaps      int    `json:"gaps"`      // Найдено пропусков
+	Recovered int64  `json:"recovered"` // Восстановлено цен
+	Error     string `json:"error,omitempty"`
+}
+
+type GapReport struct {
+	Coin      string   `json:"coin"`
+	Quote     string   `json:"quote"`
+	From      int64    `json:"from"`
+	To        int64    `json:"to"`
+	Threshold string   `json:"threshold"` // Пропуски длиннее порога считаются пропусками
+	Gaps      []Gap    `json:"gaps"`
+	LastScan  *GapScan `json:"last_scan"` // null, если фоновая задача ещё не проверяла валюту
+}
+
+// Статусы задач загрузки истории
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// BackfillJob - задача загрузки истории цен валюты за период [from, to] частями
+type BackfillJob struct {
+	ID        int64   `json:"id"`
+	Coin      string  `json:"coin"`
+	Quote     string  `json:"quote"`
+	From      int64   `json:"from"`
+	To        int64   `json:"to"`
+	Cursor    int64   `json:"cursor"`   // История загружена до этого времени
+	Status    string  `json:"status"`   // queued, running, done, failed
+	Progress  float64 `json:"progress"` // Доля загруженного периода в процентах
+	Inserted  int64   `json:"inserted"` // Добавлено цен
+	Error     string  `json:"error,omitempty"`
+	CreatedAt int64   `json:"created_at"`
+	UpdatedAt int64   `json:"updated_at"`
+}
+
+// Причины отклонения цены при проверке перед сохранением
+const (
+	RejectNonPositive = "non_positive_price" // Цена не больше нуля
+	RejectFuture      = "future_timestamp"   // Время цены в будущем
+	RejectStale       = "stale_timestamp"    // Время цены раньше последней сохранённой
+	RejectSpike       = "spike"              // Отклонение от скользящей медианы больше допустимого
+	RejectDuplicate   = "duplicate"          // Цена на это время уже сохранена
+)
+
+// QuarantinedSample - цена, отклонённая проверкой и сохранённая отдельно для разбора
+type QuarantinedSample struct {
+	ID        int64    `json:"id"`
+	Coin      string   `json:"coin"`
+	Quote     string   `json:"quote"`
+	Price     float64  `json:"price"`
+	Timestamp int64    `json:"timestamp"`
+	Reason    string   `json:"reason"`
+	Detail    string   `json:"detail,omitempty"`
+	Median    *float64 `json:"median,omitempty"` // Скользящая медиана на момент проверки (для spike)
+	CreatedAt int64    `json:"created_at"`
+}
+
+// PriceWindow - сохранённые цены валюты, с которыми сравнивается новая цена
+type PriceWindow struct {
+	Last    *int64   // Время последней сохранённой цены, nil если цен нет
+	Median  *float64 // Медиана цен окна, nil если цен в окне нет
+	Samples int      // Число цен в окне
+}
+
+// SampleStats - число сохранённых и отклонённых цен валюты за период
+type SampleStats struct {
+	Samples     int64    // Сохранено цен
+	Distinct    int64    // Различных моментов времени
+	Quarantined int64    // Отклонено проверкой, кроме повторов
+	Duplicates  int64    // Повторные цены на уже сохранённое время
+	Latency     *int64   // Медианная задержка сохранения, nil если неизвестна
+	Providers   []string // Источники цен
+}
+
+// DataQuality - отчёт о полноте и надёжности ряда цен валюты за окно
+type DataQuality struct {
+	Coin                string   `json:"coin"`
+	Quote               string   `json:"quote"`
+	Window              string   `json:"window"`
+	From                int64    `json:"from"`
+	To                  int64    `json:"to"`
+	Interval            string   `json:"interval"`              // Интервал сбора цен
+	Expected            int64    `json:"expected"`              // Ожидаемое число цен при сборе без пропусков
+	Actual              int64    `json:"actual"`                // Сохранено цен
+	Coverage            float64  `json:"coverage"`              // Различные моменты времени от ожидаемого числа, в процентах
+	Duplicates          int64    `json:"duplicates"`            // Цены на уже сохранённое время, отклонённые при сохранении
+	Quarantined         int64    `json:"quarantined"`           // Цены, отклонённые проверкой перед сохранением
+	LargestGaps         []Gap    `json:"largest_gaps"`          // Самые длинные промежутки между ценами
+	MedianIngestLatency *int64   `json:"median_ingest_latency"` // Медианная задержка между временем цены и её получением коллектором, мс
+	Providers           []string `json:"providers"`             // Источники цен
+}
+
+// Состояния горутины сбора цен
+const (
+	CollectorPending    = "pending_validation" // Валюта добавлена без проверки, проверка повторяется в фоне
+	CollectorRunning    = "running"
+	CollectorRestarting = "restarting" // Сбор упал с паникой и будет перезапущен
+	CollectorFailed     = "failed"     // Сбор падал слишком часто и остановлен до удаления пары
+)
+
+// CollectorStatus - состояние сбора цен пары валюта/котировка
+type CollectorStatus struct {
+	Coin              string `json:"coin"`
+	Quote             string `json:"quote"`
+	State             string `json:"state"`
+	StartedAt         int64  `json:"started_at"`
+	LastSuccess       int64  `json:"last_success,omitempty"`    // Время последней сохранённой цены
+	LastError         string `json:"last_error,omitempty"`      // Последняя ошибка сбора
+	LastErrorKind     string `json:"last_error_kind,omitempty"` // not_found, rate_limited, unauthorized, upstream, malformed, internal
+	LastErrorAt       int64  `json:"last_error_at,omitempty"`
+	ConsecutiveErrors int    `json:"consecutive_errors"` // Ошибок подряд с последней сохранённой цены
+	Crashes           int    `json:"crashes"`            // Паник с момента добавления пары
+	LastCrash         string `json:"last_crash,omitempty"`
+	LastCrashAt       int64  `json:"last_crash_at,omitempty"`
 }
@@ -0,0 +1,132 @@
+package rates
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"errors"
+	"fmt"
+)
+
+const (
+	LegPrice = "price"
+	LegFX    = "fx"
+)
+
+// IsFiat проверяет, что code - USD или фиатная валюта, курсы которой собираются в fx_rates
+func (s *Service) IsFiat(code string) bool {
+	return code == models.DefaultQuote || s.fiat[code]
+}
+
+// Convert пересчитывает amount из from в to на момент at.
+// from и to - название криптовалюты (Bitcoin), её тикер (BTC) или код валюты (USD, EUR).
+// Тикер переводится в название, под которым хранятся цены (см. Names), а как котировка используется как есть.
+// Цены ищутся так же, как в GetPrice (последняя цена не позже at); если прямой котировки нет,
+// пересчёт идёт через USD: для фиатных валют (см. IsFiat) - по курсам fx_rates, для остальных - по ценам в USD.
+func (s *Service) Convert(ctx context.Context, amount float64, from, to string, at int64) (models.Conversion, error) {
+	const op = "rates.Convert"
+
+	conversion := models.Conversion{Amount: amount, From: from, To: to, At: at, Rate: 1}
+	if from == to {
+		conversion.Result = amount
+		conversion.Legs = make([]models.ConversionLeg, 0)
+		return conversion, nil
+	}
+
+	legs, err := s.directLegs(ctx, from, to, at)
+	if errors.Is(err, storage.ErrPriceNotFound) {
+		legs, err = s.usdLegs(ctx, from, to, at)
+	}
+	if err != nil {
+		return models.Conversion{}, fmt.Errorf("%s; %w", op, err)
+	}
+
+	for _, leg := range legs {
+		conversion.Rate *= leg.Rate
+	}
+	conversion.Result = amount * conversion.Rate
+	conversion.Legs = legs
+	return conversion, nil
+}
+
+// directLegs ищет цену from прямо в котировке to (например Ethereum в BTC) или цену to в котировке from.
+// Фиатная валюта не бывает отслеживаемой криптовалютой, поэтому в этих случаях запрос не выполняется.
+func (s *Service) directLegs(ctx context.Context, from, to string, at int64) ([]models.ConversionLeg, error) {
+	if !s.IsFiat(from) {
+		leg, err := s.priceLeg(ctx, from, to, at)
+		if !errors.Is(err, storage.ErrPriceNotFound) {
+			return []models.ConversionLeg{leg}, err
+		}
+	}
+	if !s.IsFiat(to) {
+		leg, err := s.priceLeg(ctx, to, from, at)
+		if !errors.Is(err, storage.ErrPriceNotFound) {
+			return []models.ConversionLeg{invert(leg)}, err
+		}
+	}
+	return nil, storage.ErrPriceNotFound
+}
+
+// usdLegs пересчитывает from -> USD -> to
+func (s *Service) usdLegs(ctx context.Context, from, to string, at int64) ([]models.ConversionLeg, error) {
+	legs := make([]models.ConversionLeg, 0, 2)
+
+	if from != models.DefaultQuote {
+		leg, err := s.toUSD(ctx, from, at)
+		if err != nil {
+			return nil, err
+		}
+		legs = append(legs, leg)
+	}
+	if to != models.DefaultQuote {
+		leg, err := s.toUSD(ctx, to, at)
+		if err != nil {
+			return nil, err
+		}
+		legs = append(legs, invert(leg))
+	}
+	return legs, nil
+}
+
+// toUSD возвращает курс name -> USD: цену криптовалюты или курс фиатной валюты
+func (s *Service) toUSD(ctx context.Context, name string, at int64) (models.ConversionLeg, error) {
+	if !s.IsFiat(name) {
+		return s.priceLeg(ctx, name, models.DefaultQuote, at)
+	}
+
+	rate, err := s.storage.GetFXRate(ctx, models.DefaultQuote, name, at)
+	if err != nil {
+		return models.ConversionLeg{}, err
+	}
+	return invert(models.ConversionLeg{
+		Type:      LegFX,
+		Base:      rate.Base,
+		Quote:     rate.Quote,
+		Rate:      rate.Rate,
+		Timestamp: rate.Timestamp,
+	}), nil
+}
+
+func (s *Service) priceLeg(ctx context.Context, coin, quote string, at int64) (models.ConversionLeg, error) {
+	coin = s.names.Name(coin)
+	price, err := s.storage.GetPrice(ctx, coin, quote, at)
+	if err != nil {
+		return models.ConversionLeg{}, err
+	}
+	return models.ConversionLeg{
+		Type:      LegPrice,
+		Base:      coin,
+		Quote:     quote,
+		Rate:      price.Price,
+		Timestamp: price.Timestamp,
+	}, nil
+}
+
+// invert разворачивает курс base -> quote в quote -> base
+func invert(leg models.ConversionLeg) models.ConversionLeg {
+	leg.Base, leg.Quote = leg.Quote, leg.Base
+	if leg.Rate != 0 {
+		leg.Rate = 1 / leg.Rate
+	}
+	return leg
+}
@@ -0,0 +1,113 @@
+package rates
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type PriceStorage interface {
+	GetPrice(ctx context.Context, coin, quote string, timestamp int64) (models.Coin, error)
+	GetFXRate(ctx context.Context, from, to string, timestamp int64) (models.FXRate, error)
+	GetNearestPrice(ctx context.Context, coin, quote string, timestamp, tolerance int64) (models.Coin, error)
+	GetAlignedPrices(ctx context.Context, base, quote, via string, from, to, tolerance int64) ([][2]models.Coin, error)
+}
+
+// Names возвращает название, под которым хранятся цены валюты, по названию или тикеру (BTC -> Bitcoin)
+type Names interface {
+	Name(coin string) string
+}
+
+// Service вычисляет курсы между отслеживаемыми валютами по их ценам в общей котировке
+// и конвертирует суммы между криптовалютами и фиатными валютами
+type Service struct {
+	storage PriceStorage
+	names   Names
+	fiat    map[string]bool // Фиатные валюты, курсы которых к USD собираются в fx_rates
+}
+
+// New создаёт сервис курсов. names переводит тикеры в названия, под которыми хранятся цены;
+// fiat - коды фиатных валют, курсы которых собирает коллектор курсов (FX_CURRENCIES).
+func New(storage PriceStorage, names Names, fiat []string) *Service {
+	s := &Service{storage: storage, names: names, fiat: make(map[string]bool, len(fiat))}
+	for _, code := range fiat {
+		if code = strings.TrimSpace(code); code != "" {
+			s.fiat[models.NormalizeQuote(code)] = true
+		}
+	}
+	return s
+}
+
+// Cross возвращает курс base/quote в момент at. Обе цены должны быть не дальше tolerance от at,
+// иначе возвращается storage.ErrPriceNotFound.
+func (s *Service) Cross(ctx context.Context, base, quote, via string, at int64, tolerance time.Duration) (models.CrossRate, error) {
+	const op = "rates.Cross"
+
+	basePrice, err := s.storage.GetNearestPrice(ctx, base, via, at, tolerance.Milliseconds())
+	if err != nil {
+		return models.CrossRate{}, fmt.Errorf("%s; base leg: %w", op, err)
+	}
+	quotePrice, err := s.storage.GetNearestPrice(ctx, quote, via, at, tolerance.Milliseconds())
+	if err != nil {
+		return models.CrossRate{}, fmt.Errorf("%s; quote leg: %w", op, err)
+	}
+
+	rate, ok := cross(basePrice, quotePrice)
+	if !ok {
+		return models.CrossRate{}, fmt.Errorf("%s; zero %s price at %d: %w", op, quote, quotePrice.Timestamp,
+			storage.ErrPriceNotFound)
+	}
+	rate.Base, rate.Quote, rate.Via = base, quote, via
+	rate.Timestamp = at
+	return rate, nil
+}
+
+// CrossHistory возвращает курс base/quote для каждой цены base за [from, to].
+// Цены base, для которых нет цены quote в пределах tolerance, пропускаются и учитываются в Skipped.
+func (s *Service) CrossHistory(ctx context.Context, base, quote, via string, from, to int64,
+	tolerance time.Duration) (models.CrossRateHistory, error) {
+	const op = "rates.CrossHistory"
+
+	pairs, err := s.storage.GetAlignedPrices(ctx, base, quote, via, from, to, tolerance.Milliseconds())
+	if err != nil {
+		return models.CrossRateHistory{}, fmt.Errorf("%s; %w", op, err)
+	}
+
+	history := models.CrossRateHistory{
+		Base:      base,
+		Quote:     quote,
+		Via:       via,
+		Tolerance: tolerance.String(),
+		Points:    make([]models.CrossRate, 0, len(pairs)),
+	}
+	for _, pair := range pairs {
+		rate, ok := cross(pair[0], pair[1])
+		if !ok {
+			history.Skipped++
+			continue
+		}
+		rate.Base, rate.Quote, rate.Via = base, quote, via
+		rate.Timestamp = pair[0].Timestamp
+		history.Points = append(history.Points, rate)
+	}
+	if len(history.Points) == 0 {
+		return history, fmt.Errorf("%s; no aligned %s and %s prices: %w", op, base, quote, storage.ErrPriceNotFound)
+	}
+	return history, nil
+}
+
+func cross(base, quote models.Coin) (models.CrossRate, bool) {
+	if quote.Timestamp == 0 || quote.Price == 0 {
+		return models.CrossRate{}, false
+	}
+	return models.CrossRate{
+		Rate:           base.Price / quote.Price,
+		BasePrice:      base.Price,
+		BaseTimestamp:  base.Timestamp,
+		QuotePrice:     quote.Price,
+		QuoteTimestamp: quote.Timestamp,
+	}, true
+}
@@ -0,0 +1,236 @@
+package pg
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const alertColumns = `id_alert, coin, quote, condition, price, change_percent, COALESCE(change_window, ''),
+        COALESCE(indicator, ''), indicator_value, COALESCE(indicator_period, 0), COALESCE(indicator_interval, ''),
+        hysteresis_percent, COALESCE(cooldown, ''), state, last_notified_at, created_at`
+
+func (s *Storage) CreateAlert(ctx context.Context, alert models.Alert) (int64, error) {
+	const op = "storage.pg.CreateAlert"
+	var id int64
+	err := s.DB.QueryRow(ctx, `
+        INSERT INTO alerts (coin, quote, condition, price, change_percent, change_window, hysteresis_percent,
+            cooldown, state, created_at, indicator, indicator_value, indicator_period, indicator_interval)
+        VALUES ($1, $10, $2, $3, $4, NULLIF($5, ''), $6, NULLIF($7, ''), $8, $9, NULLIF($11, ''), $12,
+            NULLIF($13, 0), NULLIF($14, ''))
+        RETURNING id_alert
+    `, alert.Coin, alert.Condition, alert.Price, alert.ChangePercent, alert.Window, alert.HysteresisPercent,
+		alert.Cooldown, alert.State, alert.CreatedAt, alert.Quote, alert.Indicator, alert.IndicatorValue,
+		alert.IndicatorPeriod, alert.IndicatorInterval).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s; failed to insert alert: %w", op, err)
+	}
+	return id, nil
+}
+
+func (s *Storage) GetAlert(ctx context.Context, id int64) (models.Alert, error) {
+	const op = "storage.pg.GetAlert"
+	alert, err := scanAlert(s.DB.QueryRow(ctx, `
+        SELECT `+alertColumns+`
+        FROM alerts
+        WHERE id_alert = $1
+    `, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Alert{}, fmt.Errorf("%s; %w", op, storage.ErrAlertNotFound)
+	}
+	if err != nil {
+		return models.Alert{}, fmt.Errorf("%s; failed to get alert: %w", op, err)
+	}
+	return alert, nil
+}
+
+func (s *Storage) GetAlerts(ctx context.Context) ([]models.Alert, error) {
+	const op = "storage.pg.GetAlerts"
+	rows, err := s.DB.Query(ctx, `
+        SELECT `+alertColumns+`
+        FROM alerts
+        ORDER BY id_alert
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("%s; failed to get alerts: %w", op, err)
+	}
+	return collectAlerts(op, rows)
+}
+
+func (s *Storage) GetAlertsByCoin(ctx context.Context, coin, quote string) ([]models.Alert, error) {
+	const op = "storage.pg.GetAlertsByCoin"
+	rows, err := s.DB.Query(ctx, `
+        SELECT `+alertColumns+`
+        FROM alerts
+        WHERE coin = $1 AND quote = $2
+        ORDER BY id_alert
+    `, coin, quote)
+	if err != nil {
+		return nil, fmt.Errorf("%s; failed to get alerts: %w", op, err)
+	}
+	return collectAlerts(op, rows)
+}
+
+// UpdateAlert заменяет условия правила и сбрасывает его состояние в alert.State вместе с паузой между оповещениями.
+// Если состояние правила меняется, сброс записывается в историю переходов (с price = 0 и временем updatedAt).
+func (s *Storage) UpdateAlert(ctx context.Context, alert models.Alert, updatedAt int64) error {
+	const op = "storage.pg.UpdateAlert"
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s; failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var state string
+	err = tx.QueryRow(ctx, `
+        SELECT state
+        FROM alerts
+        WHERE id_alert = $1
+        FOR UPDATE
+    `, alert.ID).Scan(&state)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s; %w", op, storage.ErrAlertNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("%s; failed to get alert: %w", op, err)
+	}
+
+	_, err = tx.Exec(ctx, `
+        UPDATE alerts
+        SET coin = $2, condition = $3, price = $4, change_percent = $5, change_window = NULLIF($6, ''),
+            hysteresis_percent = $7, cooldown = NULLIF($8, ''), state = $9, quote = $10,
+            indicator = NULLIF($11, ''), indicator_value = $12, indicator_period = NULLIF($13, 0),
+            indicator_interval = NULLIF($14, ''), last_notified_at = NULL
+        WHERE id_alert = $1
+    `, alert.ID, alert.Coin, alert.Condition, alert.Price, alert.ChangePercent, alert.Window,
+		alert.HysteresisPercent, alert.Cooldown, alert.State, alert.Quote, alert.Indicator, alert.IndicatorValue,
+		alert.IndicatorPeriod, alert.IndicatorInterval)
+	if err != nil {
+		return fmt.Errorf("%s; failed to update alert: %w", op, err)
+	}
+
+	if state != alert.State {
+		_, err = tx.Exec(ctx, `
+            INSERT INTO alert_events (id_alert, from_state, to_state, price, fixation_time, notified, created_at)
+            VALUES ($1, $2, $3, 0, $4, false, $4)
+        `, alert.ID, state, alert.State, updatedAt)
+		if err != nil {
+			return fmt.Errorf("%s; failed to insert alert event: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s; failed to commit transaction: %w", op, err)
+	}
+	return nil
+}
+
+// TransitionAlert переводит правило в новое состояние и записывает переход в историю.
+// Если правило уже не в состоянии event.FromState (его изменили параллельно), переход не выполняется.
+func (s *Storage) TransitionAlert(ctx context.Context, event models.AlertEvent) error {
+	const op = "storage.pg.TransitionAlert"
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s; failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+        UPDATE alerts
+        SET state = $3,
+            last_notified_at = CASE WHEN $4 THEN $5 ELSE last_notified_at END
+        WHERE id_alert = $1 AND state = $2
+    `, event.AlertID, event.FromState, event.ToState, event.Notified, event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%s; failed to update alert: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s; %w", op, storage.ErrAlertNotFound)
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO alert_events (id_alert, from_state, to_state, price, fixation_time, notified, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+    `, event.AlertID, event.FromState, event.ToState, event.Price, event.Timestamp, event.Notified, event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%s; failed to insert alert event: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s; failed to commit transaction: %w", op, err)
+	}
+	return nil
+}
+
+func (s *Storage) GetAlertEvents(ctx context.Context, id int64) ([]models.AlertEvent, error) {
+	const op = "storage.pg.GetAlertEvents"
+	rows, err := s.DB.Query(ctx, `
+        SELECT id_event, id_alert, from_state, to_state, price, fixation_time, notified, created_at
+        FROM alert_events
+        WHERE id_alert = $1
+        ORDER BY created_at, id_event
+    `, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s; failed to get alert events: %w", op, err)
+	}
+	defer rows.Close()
+
+	events := make([]models.AlertEvent, 0)
+	for rows.Next() {
+		var event models.AlertEvent
+		if err := rows.Scan(&event.ID, &event.AlertID, &event.FromState, &event.ToState, &event.Price,
+			&event.Timestamp, &event.Notified, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s; failed to scan alert event: %w", op, err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s; failed to read alert events: %w", op, err)
+	}
+	return events, nil
+}
+
+func (s *Storage) DeleteAlert(ctx context.Context, id int64) error {
+	const op = "storage.pg.DeleteAlert"
+	tag, err := s.DB.Exec(ctx, `
+        DELETE FROM alerts
+        WHERE id_alert = $1
+    `, id)
+	if err != nil {
+		return fmt.Errorf("%s; failed to delete alert: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s; %w", op, storage.ErrAlertNotFound)
+	}
+	return nil
+}
+
+func scanAlert(row pgx.Row) (models.Alert, error) {
+	var alert models.Alert
+	err := row.Scan(&alert.ID, &alert.Coin, &alert.Quote, &alert.Condition, &alert.Price, &alert.ChangePercent,
+		&alert.Window, &alert.Indicator, &alert.IndicatorValue, &alert.IndicatorPeriod, &alert.IndicatorInterval,
+		&alert.HysteresisPercent, &alert.Cooldown, &alert.State, &alert.LastNotifiedAt,
+		&alert.CreatedAt)
+	return alert, err
+}
+
+func collectAlerts(op string, rows pgx.Rows) ([]models.Alert, error) {
+	defer rows.Close()
+
+	alerts := make([]models.Alert, 0)
+	for rows.Next() {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s; failed to scan alert: %w", op, err)
+		}
+		alerts = append(alerts, alert)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s; failed to read alerts: %w", op, err)
+	}
+	return alerts, nil
+}
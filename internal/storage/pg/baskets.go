@@ -0,0 +1,178 @@
+package pg
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *Storage) CreateBasket(ctx context.Context, basket models.Basket) (int64, error) {
+	const op = "storage.pg.CreateBasket"
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s; failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var id int64
+	err = tx.QueryRow(ctx, `
+        INSERT INTO baskets (name, quote, base_time, rebalance, created_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id_basket
+    `, basket.Name, basket.Quote, basket.BaseTime, basket.Rebalance, basket.CreatedAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s; failed to insert basket: %w", op, err)
+	}
+
+	for _, component := range basket.Components {
+		_, err := tx.Exec(ctx, `
+            INSERT INTO basket_components (id_basket, coin, weight)
+            VALUES ($1, $2, $3)
+        `, id, component.Coin, component.Weight)
+		if err != nil {
+			return 0, fmt.Errorf("%s; failed to insert basket component: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("%s; failed to commit transaction: %w", op, err)
+	}
+	return id, nil
+}
+
+func (s *Storage) GetBaskets(ctx context.Context) ([]models.Basket, error) {
+	const op = "storage.pg.GetBaskets"
+	rows, err := s.DB.Query(ctx, `
+        SELECT b.id_basket, b.name, b.quote, b.base_time, b.rebalance, b.created_at, c.coin, c.weight
+        FROM baskets b
+        JOIN basket_components c ON c.id_basket = b.id_basket
+        ORDER BY b.id_basket, c.coin
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("%s; failed to get baskets: %w", op, err)
+	}
+	defer rows.Close()
+
+	baskets := make([]models.Basket, 0)
+	for rows.Next() {
+		var (
+			basket    models.Basket
+			component models.BasketComponent
+		)
+		if err := rows.Scan(&basket.ID, &basket.Name, &basket.Quote, &basket.BaseTime, &basket.Rebalance,
+			&basket.CreatedAt, &component.Coin, &component.Weight); err != nil {
+			return nil, fmt.Errorf("%s; failed to scan basket: %w", op, err)
+		}
+		if n := len(baskets); n == 0 || baskets[n-1].ID != basket.ID {
+			basket.Components = make([]models.BasketComponent, 0)
+			baskets = append(baskets, basket)
+		}
+		last := &baskets[len(baskets)-1]
+		last.Components = append(last.Components, component)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s; failed to read baskets: %w", op, err)
+	}
+	return baskets, nil
+}
+
+func (s *Storage) GetBasket(ctx context.Context, id int64) (models.Basket, error) {
+	const op = "storage.pg.GetBasket"
+	var basket models.Basket
+	err := s.DB.QueryRow(ctx, `
+        SELECT id_basket, name, quote, base_time, rebalance, created_at
+        FROM baskets
+        WHERE id_basket = $1
+    `, id).Scan(&basket.ID, &basket.Name, &basket.Quote, &basket.BaseTime, &basket.Rebalance, &basket.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Basket{}, fmt.Errorf("%s; %w", op, storage.ErrBasketNotFound)
+	}
+	if err != nil {
+		return models.Basket{}, fmt.Errorf("%s; failed to get basket: %w", op, err)
+	}
+
+	rows, err := s.DB.Query(ctx, `
+        SELECT coin, weight
+        FROM basket_components
+        WHERE id_basket = $1
+        ORDER BY coin
+    `, id)
+	if err != nil {
+		return models.Basket{}, fmt.Errorf("%s; failed to get basket components: %w", op, err)
+	}
+	defer rows.Close()
+
+	basket.Components = make([]models.BasketComponent, 0)
+	for rows.Next() {
+		var component models.BasketComponent
+		if err := rows.Scan(&component.Coin, &component.Weight); err != nil {
+			return models.Basket{}, fmt.Errorf("%s; failed to scan basket component: %w", op, err)
+		}
+		basket.Components = append(basket.Components, component)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Basket{}, fmt.Errorf("%s; failed to read basket components: %w", op, err)
+	}
+	return basket, nil
+}
+
+func (s *Storage) DeleteBasket(ctx context.Context, id int64) error {
+	const op = "storage.pg.DeleteBasket"
+	tag, err := s.DB.Exec(ctx, `
+        DELETE FROM baskets
+        WHERE id_basket = $1
+    `, id)
+	if err != nil {
+		return fmt.Errorf("%s; failed to delete basket: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s; %w", op, storage.ErrBasketNotFound)
+	}
+	return nil
+}
+
+// GetPricesAt возвращает для каждого момента из at и каждой валюты из coins последнюю цену
+// в котировке quote не позже этого момента. Результат упорядочен по моменту и валюте.
+func (s *Storage) GetPricesAt(ctx context.Context, coins []string, quote string, at []int64) ([]models.SampledPrice, error) {
+	const op = "storage.pg.GetPricesAt"
+	rows, err := s.DB.Query(ctx, `
+        SELECT t.at, c.name, p.price, p.fixation_time
+        FROM unnest($1::bigint[]) t(at)
+        CROSS JOIN unnest($2::text[]) c(name)
+        LEFT JOIN LATERAL (
+            SELECT price, fixation_time
+            FROM coins
+            WHERE name = c.name AND quote = $3 AND fixation_time <= t.at
+            ORDER BY fixation_time DESC
+            LIMIT 1
+        ) p ON true
+        ORDER BY t.at, c.name
+    `, at, coins, quote)
+	if err != nil {
+		return nil, fmt.Errorf("%s; failed to get prices: %w", op, err)
+	}
+	defer rows.Close()
+
+	prices := make([]models.SampledPrice, 0, len(at)*len(coins))
+	for rows.Next() {
+		var (
+			price     models.SampledPrice
+			priceTime *int64
+		)
+		if err := rows.Scan(&price.At, &price.Coin, &price.Price, &priceTime); err != nil {
+			return nil, fmt.Errorf("%s; failed to scan price: %w", op, err)
+		}
+		if priceTime != nil {
+			price.Timestamp = *priceTime
+		}
+		prices = append(prices, price)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s; failed to read prices: %w", op, err)
+	}
+	return prices, nil
+}
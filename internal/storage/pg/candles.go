@@ -0,0 +1,43 @@
+package pg
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"fmt"
+)
+
+// GetCandles группирует цены валюты за [from, to) в свечи длиной interval миллисекунд.
+// Свечи выровнены по interval от начала эпохи; интервалы без цен пропускаются.
+func (s *Storage) GetCandles(ctx context.Context, coin, quote string, from, to, interval int64) ([]models.Candle, error) {
+	const op = "storage.pg.GetCandles"
+	rows, err := s.DB.Query(ctx, `
+        SELECT fixation_time / $5 * $5 AS bucket,
+               (array_agg(price ORDER BY fixation_time))[1],
+               max(price),
+               min(price),
+               (array_agg(price ORDER BY fixation_time DESC))[1],
+               count(*)
+        FROM coins
+        WHERE name = $1 AND quote = $2 AND fixation_time >= $3 AND fixation_time < $4
+        GROUP BY bucket
+        ORDER BY bucket
+    `, coin, quote, from, to, interval)
+	if err != nil {
+		return nil, fmt.Errorf("%s; failed to get candles: %w", op, err)
+	}
+	defer rows.Close()
+
+	candles := make([]models.Candle, 0)
+	for rows.Next() {
+		var candle models.Candle
+		if err := rows.Scan(&candle.Timestamp, &candle.Open, &candle.High, &candle.Low, &candle.Close,
+			&candle.Samples); err != nil {
+			return nil, fmt.Errorf("%s; failed to scan candle: %w", op, err)
+		}
+		candles = append(candles, candle)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s; failed to read candles: %w", op, err)
+	}
+	return candles, nil
+}
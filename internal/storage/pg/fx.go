@@ -0,0 +1,200 @@
+package pg
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// fxRateAt возвращает подзапрос для LATERAL JOIN: курс USD -> quote, действовавший в момент at.
+// Для USD курс равен 1. Все курсы хранятся относительно USD (models.DefaultQuote).
+func fxRateAt(quote, at string) string {
+	return `
+        SELECT 1::numeric AS rate, NULL::bigint AS fixation_time
+        WHERE ` + quote + ` = '` + models.DefaultQuote + `'
+        UNION ALL
+        (SELECT rate, fixation_time
+         FROM fx_rates
+         WHERE base = '` + models.DefaultQuote + `' AND quote = ` + quote + ` AND fixation_time <= ` + at + `
+         ORDER BY fixation_time DESC
+         LIMIT 1)
+        LIMIT 1`
+}
+
+func (s *Storage) AddFXRates(ctx context.Context, rates []models.FXRate) error {
+	const op = "storage.pg.AddFXRates"
+	batch := &pgx.Batch{}
+	for _, rate := range rates {
+		batch.Queue(`
+            INSERT INTO fx_rates (base, quote, rate, fixation_time)
+            VALUES ($1, $2, $3, $4)
+            ON CONFLICT (base, quote, fixation_time) DO UPDATE SET rate = EXCLUDED.rate
+        `, rate.Base, rate.Quote, rate.Rate, rate.Timestamp)
+	}
+	if err := s.DB.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("%s; failed to insert fx rates: %w", op, err)
+	}
+	return nil
+}
+
+// GetFXRate возвращает курс from -> to, действовавший в момент timestamp.
+// Курс между двумя валютами, отличными от USD, вычисляется через USD.
+// Timestamp результата - время более старого из использованных курсов.
+func (s *Storage) GetFXRate(ctx context.Context, from, to string, timestamp int64) (models.FXRate, error) {
+	const op = "storage.pg.GetFXRate"
+	var (
+		toRate, fromRate *float64
+		toTime, fromTime *int64
+	)
+	err := s.DB.QueryRow(ctx, `
+        SELECT fx_to.rate, fx_to.fixation_time, fx_from.rate, fx_from.fixation_time
+        FROM (SELECT $1::text AS from_quote, $2::text AS to_quote, $3::bigint AS at) p
+        LEFT JOIN LATERAL (`+fxRateAt("p.to_quote", "p.at")+`) fx_to ON true
+        LEFT JOIN LATERAL (`+fxRateAt("p.from_quote", "p.at")+`) fx_from ON true
+    `, from, to, timestamp).Scan(&toRate, &toTime, &fromRate, &fromTime)
+	if err != nil {
+		return models.FXRate{}, fmt.Errorf("%s; failed to get fx rate: %w", op, err)
+	}
+	if toRate == nil || fromRate == nil {
+		return models.FXRate{}, fmt.Errorf("%s; %s -> %s at %d: %w", op, from, to, timestamp, storage.ErrFXRateNotFound)
+	}
+
+	rate := models.FXRate{
+		Base:      from,
+		Quote:     to,
+		Rate:      *toRate / *fromRate,
+		Timestamp: timestamp,
+	}
+	for _, t := range []*int64{toTime, fromTime} {
+		if t != nil && *t < rate.Timestamp {
+			rate.Timestamp = *t
+		}
+	}
+	return rate, nil
+}
+
+// GetConvertedPrice работает как GetPrice, но пересчитывает цену в фиатную валюту convert
+// по курсу, действовавшему в момент найденной цены.
+func (s *Storage) GetConvertedPrice(ctx context.Context, coin, quote, convert string, timestamp int64) (models.Coin, error) {
+	const op = "storage.pg.GetConvertedPrice"
+	var (
+		coinInfo models.Coin
+		price    *float64
+	)
+	err := s.DB.QueryRow(ctx, `
+        WITH p AS (
+            SELECT name, quote, price, fixation_time
+            FROM coins
+            WHERE name = $1 AND quote = $2 AND fixation_time <= $3
+            ORDER BY ABS(fixation_time - $3)
+            LIMIT 1
+        )
+        SELECT p.name, p.price * fx_to.rate / fx_from.rate, p.fixation_time
+        FROM p
+        LEFT JOIN LATERAL (`+fxRateAt("$4::text", "p.fixation_time")+`) fx_to ON true
+        LEFT JOIN LATERAL (`+fxRateAt("p.quote", "p.fixation_time")+`) fx_from ON true
+    `, coin, quote, timestamp, convert).Scan(&coinInfo.Name, &price, &coinInfo.Timestamp)
+	if err != nil {
+		return models.Coin{}, fmt.Errorf("%s; failed to get coin: %w", op, err)
+	}
+	if price == nil {
+		return models.Coin{}, fmt.Errorf("%s; %s -> %s at %d: %w", op, quote, convert, coinInfo.Timestamp,
+			storage.ErrFXRateNotFound)
+	}
+	coinInfo.Quote = convert
+	coinInfo.Price = *price
+	return coinInfo, nil
+}
+
+// GetConvertedSeries работает как GetSeries, но пересчитывает значения в фиатную валюту convert
+// по курсу, действовавшему в момент каждой точки сетки
+func (s *Storage) GetConvertedSeries(ctx context.Context, coin, quote, convert string, from, to int64,
+	resample models.Resample) ([]models.SeriesPoint, error) {
+	const op = "storage.pg.GetConvertedSeries"
+	points, err := s.GetSeries(ctx, coin, quote, from, to, resample)
+	if err != nil {
+		return nil, fmt.Errorf("%s; %w", op, err)
+	}
+
+	times := make([]int64, 0, len(points))
+	for _, point := range points {
+		if point.Value != nil {
+			times = append(times, point.Timestamp)
+		}
+	}
+	rates, err := s.convertRates(ctx, quote, convert, times)
+	if err != nil {
+		return nil, fmt.Errorf("%s; %w", op, err)
+	}
+	for i, point := range points {
+		if point.Value != nil {
+			value := *point.Value * rates[point.Timestamp]
+			points[i].Value = &value
+		}
+	}
+	return points, nil
+}
+
+// GetConvertedCandles работает как GetCandles, но пересчитывает свечи в фиатную валюту convert
+// по курсу, действовавшему в начале каждой свечи
+func (s *Storage) GetConvertedCandles(ctx context.Context, coin, quote, convert string, from, to, interval int64) ([]models.Candle, error) {
+	const op = "storage.pg.GetConvertedCandles"
+	candles, err := s.GetCandles(ctx, coin, quote, from, to, interval)
+	if err != nil {
+		return nil, fmt.Errorf("%s; %w", op, err)
+	}
+
+	times := make([]int64, len(candles))
+	for i, candle := range candles {
+		times[i] = candle.Timestamp
+	}
+	rates, err := s.convertRates(ctx, quote, convert, times)
+	if err != nil {
+		return nil, fmt.Errorf("%s; %w", op, err)
+	}
+	for i := range candles {
+		rate := rates[candles[i].Timestamp]
+		candles[i].Open *= rate
+		candles[i].High *= rate
+		candles[i].Low *= rate
+		candles[i].Close *= rate
+	}
+	return candles, nil
+}
+
+// convertRates возвращает множители пересчёта из quote в convert по курсам, действовавшим в моменты times.
+// Если в какой-либо момент курса нет, возвращается storage.ErrFXRateNotFound.
+func (s *Storage) convertRates(ctx context.Context, quote, convert string, times []int64) (map[int64]float64, error) {
+	rows, err := s.DB.Query(ctx, `
+        SELECT t, fx_to.rate / fx_from.rate
+        FROM unnest($3::bigint[]) AS t
+        LEFT JOIN LATERAL (`+fxRateAt("$2::text", "t")+`) fx_to ON true
+        LEFT JOIN LATERAL (`+fxRateAt("$1::text", "t")+`) fx_from ON true
+    `, quote, convert, times)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fx rates: %w", err)
+	}
+	defer rows.Close()
+
+	rates := make(map[int64]float64, len(times))
+	for rows.Next() {
+		var (
+			t    int64
+			rate *float64
+		)
+		if err := rows.Scan(&t, &rate); err != nil {
+			return nil, fmt.Errorf("failed to scan fx rate: %w", err)
+		}
+		if rate == nil {
+			return nil, fmt.Errorf("%s -> %s at %d: %w", quote, convert, t, storage.ErrFXRateNotFound)
+		}
+		rates[t] = *rate
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read fx rates: %w", err)
+	}
+	return rates, nil
+}
@@ -0,0 +1,67 @@
+package pg
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"fmt"
+)
+
+// GetGaps возвращает промежутки длиннее threshold миллисекунд между соседними ценами валюты за период [from, to]
+func (s *Storage) GetGaps(ctx context.Context, coin, quote string, from, to, threshold int64) ([]models.Gap, error) {
+	const op = "storage.pg.GetGaps"
+	rows, err := s.DB.Query(ctx, `
+        SELECT prev, fixation_time
+        FROM (
+            SELECT fixation_time, lag(fixation_time) OVER (ORDER BY fixation_time) AS prev
+            FROM coins
+            WHERE name = $1 AND quote = $2 AND fixation_time >= $3 AND fixation_time <= $4
+        ) t
+        WHERE fixation_time - prev > $5
+        ORDER BY prev
+    `, coin, quote, from, to, threshold)
+	if err != nil {
+		return nil, fmt.Errorf("%s; failed to get gaps: %w", op, err)
+	}
+	defer rows.Close()
+
+	gaps := make([]models.Gap, 0)
+	for rows.Next() {
+		var gap models.Gap
+		if err := rows.Scan(&gap.From, &gap.To); err != nil {
+			return nil, fmt.Errorf("%s; failed to scan gap: %w", op, err)
+		}
+		gap.Duration = gap.To - gap.From
+		gaps = append(gaps, gap)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s; failed to read gaps: %w", op, err)
+	}
+	return gaps, nil
+}
+
+// AddCoins сохраняет цены, которых ещё нет (по валюте, котировке и времени), и возвращает число добавленных.
+// source - откуда получены цены (models.SourceBackfill или models.SourceGapFill).
+// Цены не проходят проверку tracker.Validator (см. его описание).
+func (s *Storage) AddCoins(ctx context.Context, coins []models.Coin, source string) (int64, error) {
+	const op = "storage.pg.AddCoins"
+	names, quotes := make([]string, len(coins)), make([]string, len(coins))
+	prices, times := make([]float64, len(coins)), make([]int64, len(coins))
+	providers, providerTimes, ingested := make([]*string, len(coins)), make([]*float64, len(coins)), make([]*int64, len(coins))
+	for i, coin := range coins {
+		names[i], quotes[i], prices[i], times[i] = coin.Name, coin.Quote, coin.Price, coin.Timestamp
+		providers[i], providerTimes[i], ingested[i] = provenance(coin)
+	}
+
+	tag, err := s.DB.Exec(ctx, `
+        INSERT INTO coins (name, quote, price, fixation_time, provider, provider_time, ingested_at, source)
+        SELECT DISTINCT ON (n.name, n.quote, n.fixation_time)
+               n.name, n.quote, n.price, n.fixation_time, n.provider, n.provider_time, n.ingested_at, $8
+        FROM unnest($1::text[], $2::text[], $3::float8[], $4::bigint[], $5::text[], $6::float8[], $7::bigint[])
+             AS n(name, quote, price, fixation_time, provider, provider_time, ingested_at)
+        ON CONFLICT (name, quote, fixation_time) DO NOTHING
+    `, names, quotes, prices, times, providers, providerTimes, ingested, source)
+	if err != nil {
+		return 0, fmt.Errorf("%s; failed to insert coins: %w", op, err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,96 @@
+package pg
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const jobColumns = `id_job, coin, quote, from_time, to_time, cursor_time, status, inserted, error, created_at, updated_at`
+
+func (s *Storage) CreateBackfillJob(ctx context.Context, job models.BackfillJob) (int64, error) {
+	const op = "storage.pg.CreateBackfillJob"
+	var id int64
+	err := s.DB.QueryRow(ctx, `
+        INSERT INTO backfill_jobs (coin, quote, from_time, to_time, cursor_time, status, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id_job
+    `, job.Coin, job.Quote, job.From, job.To, job.Cursor, job.Status, job.CreatedAt, job.UpdatedAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s; failed to insert backfill job: %w", op, err)
+	}
+	return id, nil
+}
+
+func (s *Storage) GetBackfillJob(ctx context.Context, id int64) (models.BackfillJob, error) {
+	const op = "storage.pg.GetBackfillJob"
+	job, err := scanJob(s.DB.QueryRow(ctx, `SELECT `+jobColumns+` FROM backfill_jobs WHERE id_job = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.BackfillJob{}, fmt.Errorf("%s; %w", op, storage.ErrJobNotFound)
+	}
+	if err != nil {
+		return models.BackfillJob{}, fmt.Errorf("%s; failed to get backfill job: %w", op, err)
+	}
+	return job, nil
+}
+
+// GetPendingBackfillJobs возвращает незавершённые задачи (queued и running) в порядке создания
+func (s *Storage) GetPendingBackfillJobs(ctx context.Context) ([]models.BackfillJob, error) {
+	const op = "storage.pg.GetPendingBackfillJobs"
+	rows, err := s.DB.Query(ctx, `
+        SELECT `+jobColumns+`
+        FROM backfill_jobs
+        WHERE status IN ($1, $2)
+        ORDER BY id_job
+    `, models.JobQueued, models.JobRunning)
+	if err != nil {
+		return nil, fmt.Errorf("%s; failed to get backfill jobs: %w", op, err)
+	}
+	jobs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.BackfillJob, error) {
+		return scanJob(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s; failed to read backfill jobs: %w", op, err)
+	}
+	return jobs, nil
+}
+
+// UpdateBackfillJob сохраняет состояние задачи: статус, позицию, число добавленных цен и ошибку
+func (s *Storage) UpdateBackfillJob(ctx context.Context, job models.BackfillJob) error {
+	const op = "storage.pg.UpdateBackfillJob"
+	tag, err := s.DB.Exec(ctx, `
+        UPDATE backfill_jobs
+        SET status = $2, cursor_time = $3, inserted = $4, error = $5, updated_at = $6
+        WHERE id_job = $1
+    `, job.ID, job.Status, job.Cursor, job.Inserted, job.Error, job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("%s; failed to update backfill job: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s; %w", op, storage.ErrJobNotFound)
+	}
+	return nil
+}
+
+func scanJob(row pgx.Row) (models.BackfillJob, error) {
+	var job models.BackfillJob
+	err := row.Scan(&job.ID, &job.Coin, &job.Quote, &job.From, &job.To, &job.Cursor, &job.Status, &job.Inserted,
+		&job.Error, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return models.BackfillJob{}, err
+	}
+	job.Progress = progress(job)
+	return job, nil
+}
+
+// progress - доля периода задачи, история за которую уже загружена, в процентах
+func progress(job models.BackfillJob) float64 {
+	if job.Status == models.JobDone || job.To <= job.From {
+		return 100
+	}
+	return float64(job.Cursor-job.From) / float64(job.To-job.From) * 100
+}
@@ -0,0 +1,51 @@
+package pg
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"fmt"
+)
+
+// GetLatestPrices одним запросом возвращает последнюю цену каждой валюты coins в котировке quote
+// и для каждого смещения из offsets (в миллисекундах) - последнюю цену не позже её времени минус смещение.
+func (s *Storage) GetLatestPrices(ctx context.Context, quote string, coins []string, offsets []int64) ([]models.LatestPrice, error) {
+	const op = "storage.pg.GetLatestPrices"
+	rows, err := s.DB.Query(ctx, `
+        WITH latest AS (
+            SELECT DISTINCT ON (name) name, quote, price, fixation_time
+            FROM coins
+            WHERE quote = $1 AND name = ANY($2)
+            ORDER BY name, fixation_time DESC
+        )
+        SELECT l.name, l.quote, l.price, l.fixation_time,
+               COALESCE(array_agg(p.price ORDER BY o.ord) FILTER (WHERE o.ord IS NOT NULL), '{}')
+        FROM latest l
+        LEFT JOIN unnest($3::bigint[]) WITH ORDINALITY o(offset_ms, ord) ON true
+        LEFT JOIN LATERAL (
+            SELECT price
+            FROM coins
+            WHERE name = l.name AND quote = l.quote AND fixation_time <= l.fixation_time - o.offset_ms
+            ORDER BY fixation_time DESC
+            LIMIT 1
+        ) p ON true
+        GROUP BY l.name, l.quote, l.price, l.fixation_time
+        ORDER BY l.name
+    `, quote, coins, offsets)
+	if err != nil {
+		return nil, fmt.Errorf("%s; failed to get latest prices: %w", op, err)
+	}
+	defer rows.Close()
+
+	prices := make([]models.LatestPrice, 0)
+	for rows.Next() {
+		var price models.LatestPrice
+		if err := rows.Scan(&price.Name, &price.Quote, &price.Price, &price.Timestamp, &price.Previous); err != nil {
+			return nil, fmt.Errorf("%s; failed to scan latest price: %w", op, err)
+		}
+		prices = append(prices, price)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s; failed to read latest prices: %w", op, err)
+	}
+	return prices, nil
+}
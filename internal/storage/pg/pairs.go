@@ -0,0 +1,98 @@
+package pg
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetNearestPrice возвращает цену, ближайшую к timestamp (в обе стороны), не дальше tolerance миллисекунд
+func (s *Storage) GetNearestPrice(ctx context.Context, coin, quote string, timestamp, tolerance int64) (models.Coin, error) {
+	const op = "storage.pg.GetNearestPrice"
+	var coinInfo models.Coin
+	err := s.DB.QueryRow(ctx, `
+        SELECT id_coin, name, quote, price, fixation_time
+        FROM coins
+        WHERE name = $1 AND quote = $2 AND fixation_time BETWEEN $3::bigint - $4 AND $3::bigint + $4
+        ORDER BY ABS(fixation_time - $3)
+        LIMIT 1
+    `, coin, quote, timestamp, tolerance).Scan(&coinInfo.ID, &coinInfo.Name, &coinInfo.Quote, &coinInfo.Price,
+		&coinInfo.Timestamp)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Coin{}, fmt.Errorf("%s; %s/%s at %d: %w", op, coin, quote, timestamp, storage.ErrPriceNotFound)
+	}
+	if err != nil {
+		return models.Coin{}, fmt.Errorf("%s; failed to get coin: %w", op, err)
+	}
+	return coinInfo, nil
+}
+
+// GetPriceBefore возвращает последнюю цену не позже timestamp и не раньше timestamp - tolerance миллисекунд
+func (s *Storage) GetPriceBefore(ctx context.Context, coin, quote string, timestamp, tolerance int64) (models.Coin, error) {
+	const op = "storage.pg.GetPriceBefore"
+	var coinInfo models.Coin
+	err := s.DB.QueryRow(ctx, `
+        SELECT id_coin, name, quote, price, fixation_time
+        FROM coins
+        WHERE name = $1 AND quote = $2 AND fixation_time BETWEEN $3::bigint - $4 AND $3::bigint
+        ORDER BY fixation_time DESC
+        LIMIT 1
+    `, coin, quote, timestamp, tolerance).Scan(&coinInfo.ID, &coinInfo.Name, &coinInfo.Quote, &coinInfo.Price,
+		&coinInfo.Timestamp)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Coin{}, fmt.Errorf("%s; %s/%s at %d: %w", op, coin, quote, timestamp, storage.ErrPriceNotFound)
+	}
+	if err != nil {
+		return models.Coin{}, fmt.Errorf("%s; failed to get coin: %w", op, err)
+	}
+	return coinInfo, nil
+}
+
+// GetAlignedPrices возвращает цены base за [from, to] и для каждой - ближайшую цену quote
+// не дальше tolerance миллисекунд. Если такой цены нет, второй элемент пары пустой (Timestamp == 0).
+func (s *Storage) GetAlignedPrices(ctx context.Context, base, quote, via string, from, to, tolerance int64) ([][2]models.Coin, error) {
+	const op = "storage.pg.GetAlignedPrices"
+	rows, err := s.DB.Query(ctx, `
+        SELECT b.price, b.fixation_time, q.price, q.fixation_time
+        FROM coins b
+        LEFT JOIN LATERAL (
+            SELECT price, fixation_time
+            FROM coins
+            WHERE name = $2 AND quote = $3
+              AND fixation_time BETWEEN b.fixation_time - $6::bigint AND b.fixation_time + $6::bigint
+            ORDER BY ABS(fixation_time - b.fixation_time)
+            LIMIT 1
+        ) q ON true
+        WHERE b.name = $1 AND b.quote = $3 AND b.fixation_time BETWEEN $4 AND $5
+        ORDER BY b.fixation_time
+    `, base, quote, via, from, to, tolerance)
+	if err != nil {
+		return nil, fmt.Errorf("%s; failed to get prices: %w", op, err)
+	}
+	defer rows.Close()
+
+	pairs := make([][2]models.Coin, 0)
+	for rows.Next() {
+		var (
+			pair       [2]models.Coin
+			quotePrice *float64
+			quoteTime  *int64
+		)
+		if err := rows.Scan(&pair[0].Price, &pair[0].Timestamp, &quotePrice, &quoteTime); err != nil {
+			return nil, fmt.Errorf("%s; failed to scan prices: %w", op, err)
+		}
+		pair[0].Name, pair[0].Quote = base, via
+		if quotePrice != nil {
+			pair[1] = models.Coin{Name: quote, Quote: via, Price: *quotePrice, Timestamp: *quoteTime}
+		}
+		pairs = append(pairs, pair)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s; failed to read prices: %w", op, err)
+	}
+	return pairs, nil
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"crypto_tracker/config"
 	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"errors"
 	"fmt"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -48,30 +51,94 @@ func (s *Storage) Close() {
 	defer s.DB.Close()
 }
 
-func (s *Storage) AddCoin(ctx context.Context, coin models.Coin) error {
+// AddCoin сохраняет цену, собранную коллектором (source = live), и возвращает её id.
+// Если цена валюты на это время уже сохранена, возвращает storage.ErrPriceExists.
+func (s *Storage) AddCoin(ctx context.Context, coin models.Coin) (int64, error) {
 	const op = "storage.pg.AddCoin"
-	_, err := s.DB.Exec(ctx, `
-        INSERT INTO coins (name, price, fixation_time)
-        VALUES ($1, $2, $3)
-    `, coin.Name, coin.Price, coin.Timestamp)
+	var id int64
+	provider, providerTime, ingestedAt := provenance(coin)
+	err := s.DB.QueryRow(ctx, `
+        INSERT INTO coins (name, quote, price, fixation_time, provider, provider_time, ingested_at, source)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        ON CONFLICT (name, quote, fixation_time) DO NOTHING
+        RETURNING id_coin
+    `, coin.Name, coin.Quote, coin.Price, coin.Timestamp, provider, providerTime, ingestedAt, models.SourceLive).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("%s; %s/%s at %d: %w", op, coin.Name, coin.Quote, coin.Timestamp, storage.ErrPriceExists)
+	}
 	if err != nil {
-		return fmt.Errorf("%s; failed to insert coin: %w", op, err)
+		return 0, fmt.Errorf("%s; failed to insert coin: %w", op, err)
 	}
-	return nil
+	return id, nil
 }
 
-func (s *Storage) GetPrice(ctx context.Context, coin string, timestamp int64) (models.Coin, error) {
+func (s *Storage) GetPrice(ctx context.Context, coin, quote string, timestamp int64) (models.Coin, error) {
 	const op = "storage.pg.GetPrice"
 	var coinInfo models.Coin
+	var provider *string
+	var provenance models.Provenance
 	err := s.DB.QueryRow(ctx, `
-        SELECT name, price, fixation_time
+        SELECT name, quote, price, fixation_time, provider, provider_time, ingested_at
         FROM coins
-        WHERE name = $1 AND fixation_time <= $2
+        WHERE name = $1 AND quote = $3 AND fixation_time <= $2
         ORDER BY ABS(fixation_time - $2)
         LIMIT 1
-    `, coin, timestamp).Scan(&coinInfo.Name, &coinInfo.Price, &coinInfo.Timestamp)
+    `, coin, timestamp, quote).Scan(&coinInfo.Name, &coinInfo.Quote, &coinInfo.Price, &coinInfo.Timestamp, &provider,
+		&provenance.ProviderTime, &provenance.IngestedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Coin{}, fmt.Errorf("%s; %s/%s at %d: %w", op, coin, quote, timestamp, storage.ErrPriceNotFound)
+	}
 	if err != nil {
 		return models.Coin{}, fmt.Errorf("%s; failed to get coin: %w", op, err)
 	}
+	if provider != nil {
+		provenance.Provider = *provider
+		if provenance.IngestedAt != nil {
+			latency := *provenance.IngestedAt - coinInfo.Timestamp
+			provenance.Latency = &latency
+		}
+		coinInfo.Provenance = &provenance
+	}
 	return coinInfo, nil
 }
+
+// provenance возвращает источник, исходное время и время получения цены для сохранения (NULL, если неизвестны)
+func provenance(coin models.Coin) (*string, *float64, *int64) {
+	if coin.Provenance == nil {
+		return nil, nil, nil
+	}
+	return &coin.Provenance.Provider, coin.Provenance.ProviderTime, coin.Provenance.IngestedAt
+}
+
+// GetCoinsAfter возвращает цены в котировке quote с id_coin больше afterID в порядке сохранения.
+// Пустой список coins означает все валюты. Возвращаются только цены, собранные коллектором (source = live):
+// цены из загрузки истории и дозаполнения пропусков получают большие id_coin, но не являются новыми
+// и в поток не попадали.
+func (s *Storage) GetCoinsAfter(ctx context.Context, coins []string, quote string, afterID int64, limit int) ([]models.Coin, error) {
+	const op = "storage.pg.GetCoinsAfter"
+	rows, err := s.DB.Query(ctx, `
+        SELECT id_coin, name, quote, price, fixation_time
+        FROM coins
+        WHERE id_coin > $1 AND (COALESCE(cardinality($2::text[]), 0) = 0 OR name = ANY($2))
+          AND quote = $3 AND source = $4
+        ORDER BY id_coin
+        LIMIT $5
+    `, afterID, coins, quote, models.SourceLive, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s; failed to get coins: %w", op, err)
+	}
+	defer rows.Close()
+
+	result := make([]models.Coin, 0)
+	for rows.Next() {
+		var coin models.Coin
+		if err := rows.Scan(&coin.ID, &coin.Name, &coin.Quote, &coin.Price, &coin.Timestamp); err != nil {
+			return nil, fmt.Errorf("%s; failed to scan coin: %w", op, err)
+		}
+		result = append(result, coin)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s; failed to read coins: %w", op, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,198 @@
+package pg
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *Storage) CreatePortfolio(ctx context.Context, portfolio models.Portfolio) (int64, error) {
+	const op = "storage.pg.CreatePortfolio"
+	var id int64
+	err := s.DB.QueryRow(ctx, `
+        INSERT INTO portfolios (name, created_at)
+        VALUES ($1, $2)
+        RETURNING id_portfolio
+    `, portfolio.Name, portfolio.CreatedAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s; failed to insert portfolio: %w", op, err)
+	}
+	return id, nil
+}
+
+func (s *Storage) GetPortfolios(ctx context.Context) ([]models.Portfolio, error) {
+	const op = "storage.pg.GetPortfolios"
+	rows, err := s.DB.Query(ctx, `
+        SELECT p.id_portfolio, p.name, p.created_at, h.coin, h.quantity
+        FROM portfolios p
+        LEFT JOIN holdings h ON h.id_portfolio = p.id_portfolio
+        ORDER BY p.id_portfolio, h.coin
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("%s; failed to get portfolios: %w", op, err)
+	}
+	defer rows.Close()
+
+	portfolios := make([]models.Portfolio, 0)
+	for rows.Next() {
+		var (
+			portfolio models.Portfolio
+			coin      *string
+			quantity  *float64
+		)
+		if err := rows.Scan(&portfolio.ID, &portfolio.Name, &portfolio.CreatedAt, &coin, &quantity); err != nil {
+			return nil, fmt.Errorf("%s; failed to scan portfolio: %w", op, err)
+		}
+		if n := len(portfolios); n == 0 || portfolios[n-1].ID != portfolio.ID {
+			portfolio.Holdings = make([]models.Holding, 0)
+			portfolios = append(portfolios, portfolio)
+		}
+		if coin != nil {
+			last := &portfolios[len(portfolios)-1]
+			last.Holdings = append(last.Holdings, models.Holding{Coin: *coin, Quantity: *quantity})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s; failed to read portfolios: %w", op, err)
+	}
+	return portfolios, nil
+}
+
+func (s *Storage) GetPortfolio(ctx context.Context, id int64) (models.Portfolio, error) {
+	const op = "storage.pg.GetPortfolio"
+	var portfolio models.Portfolio
+	err := s.DB.QueryRow(ctx, `
+        SELECT id_portfolio, name, created_at
+        FROM portfolios
+        WHERE id_portfolio = $1
+    `, id).Scan(&portfolio.ID, &portfolio.Name, &portfolio.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Portfolio{}, fmt.Errorf("%s; %w", op, storage.ErrPortfolioNotFound)
+	}
+	if err != nil {
+		return models.Portfolio{}, fmt.Errorf("%s; failed to get portfolio: %w", op, err)
+	}
+
+	rows, err := s.DB.Query(ctx, `
+        SELECT coin, quantity
+        FROM holdings
+        WHERE id_portfolio = $1
+        ORDER BY coin
+    `, id)
+	if err != nil {
+		return models.Portfolio{}, fmt.Errorf("%s; failed to get holdings: %w", op, err)
+	}
+	defer rows.Close()
+
+	portfolio.Holdings = make([]models.Holding, 0)
+	for rows.Next() {
+		var holding models.Holding
+		if err := rows.Scan(&holding.Coin, &holding.Quantity); err != nil {
+			return models.Portfolio{}, fmt.Errorf("%s; failed to scan holding: %w", op, err)
+		}
+		portfolio.Holdings = append(portfolio.Holdings, holding)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Portfolio{}, fmt.Errorf("%s; failed to read holdings: %w", op, err)
+	}
+	return portfolio, nil
+}
+
+func (s *Storage) DeletePortfolio(ctx context.Context, id int64) error {
+	const op = "storage.pg.DeletePortfolio"
+	tag, err := s.DB.Exec(ctx, `
+        DELETE FROM portfolios
+        WHERE id_portfolio = $1
+    `, id)
+	if err != nil {
+		return fmt.Errorf("%s; failed to delete portfolio: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s; %w", op, storage.ErrPortfolioNotFound)
+	}
+	return nil
+}
+
+// SetHolding создаёт позицию или заменяет количество существующей
+func (s *Storage) SetHolding(ctx context.Context, portfolioID int64, holding models.Holding) error {
+	const op = "storage.pg.SetHolding"
+	tag, err := s.DB.Exec(ctx, `
+        INSERT INTO holdings (id_portfolio, coin, quantity)
+        SELECT id_portfolio, $2, $3
+        FROM portfolios
+        WHERE id_portfolio = $1
+        ON CONFLICT (id_portfolio, coin) DO UPDATE SET quantity = EXCLUDED.quantity
+    `, portfolioID, holding.Coin, holding.Quantity)
+	if err != nil {
+		return fmt.Errorf("%s; failed to set holding: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s; %w", op, storage.ErrPortfolioNotFound)
+	}
+	return nil
+}
+
+func (s *Storage) DeleteHolding(ctx context.Context, portfolioID int64, coin string) error {
+	const op = "storage.pg.DeleteHolding"
+	tag, err := s.DB.Exec(ctx, `
+        DELETE FROM holdings
+        WHERE id_portfolio = $1 AND coin = $2
+    `, portfolioID, coin)
+	if err != nil {
+		return fmt.Errorf("%s; failed to delete holding: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s; %w", op, storage.ErrHoldingNotFound)
+	}
+	return nil
+}
+
+// GetPortfolioValues оценивает портфель в моменты from, from+step, ..., to.
+// Цена каждой позиции в котировке quote приводится к сетке по правилам resample (см. GetSeries).
+func (s *Storage) GetPortfolioValues(ctx context.Context, id int64, quote string, from, to int64,
+	resample models.Resample) ([]models.PortfolioValue, error) {
+	const op = "storage.pg.GetPortfolioValues"
+	portfolio, err := s.GetPortfolio(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s; %w", op, err)
+	}
+	if len(portfolio.Holdings) == 0 {
+		return make([]models.PortfolioValue, 0), nil
+	}
+
+	values := make([]models.PortfolioValue, 0)
+	for t := from; t <= to; t += resample.Step {
+		values = append(values, models.PortfolioValue{
+			Timestamp: t,
+			Quote:     quote,
+			Holdings:  make([]models.HoldingValue, 0, len(portfolio.Holdings)),
+		})
+	}
+	for _, holding := range portfolio.Holdings {
+		series, err := s.GetSeries(ctx, holding.Coin, quote, from, to, resample)
+		if err != nil {
+			return nil, fmt.Errorf("%s; %s: %w", op, holding.Coin, err)
+		}
+		for i, point := range series {
+			value := &values[i]
+			if point.Value == nil {
+				value.Missing = append(value.Missing, holding.Coin)
+				continue
+			}
+			holdingValue := models.HoldingValue{
+				Coin:      holding.Coin,
+				Quantity:  holding.Quantity,
+				Price:     *point.Value,
+				Value:     holding.Quantity * *point.Value,
+				Timestamp: point.PriceTime,
+			}
+			value.Total += holdingValue.Value
+			value.Holdings = append(value.Holdings, holdingValue)
+		}
+	}
+	return values, nil
+}
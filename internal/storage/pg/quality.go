@@ -0,0 +1,63 @@
+package pg
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetSampleStats считает сохранённые цены валюты, различные моменты времени, отклонённые цены и повторы за период [from, to],
+// медианную задержку сохранения (по ценам, полученным коллектором) и источники цен
+func (s *Storage) GetSampleStats(ctx context.Context, coin, quote string, from, to int64) (models.SampleStats, error) {
+	const op = "storage.pg.GetSampleStats"
+	var stats models.SampleStats
+	err := s.DB.QueryRow(ctx, `
+        SELECT count(*),
+               count(DISTINCT fixation_time),
+               (SELECT count(*) FROM quarantined_coins
+                WHERE name = $1 AND quote = $2 AND fixation_time >= $3 AND fixation_time <= $4
+                  AND reason <> $5),
+               (SELECT count(*) FROM quarantined_coins
+                WHERE name = $1 AND quote = $2 AND fixation_time >= $3 AND fixation_time <= $4
+                  AND reason = $5),
+               round(percentile_cont(0.5) WITHIN GROUP (ORDER BY ingested_at - fixation_time))::bigint,
+               COALESCE(array_agg(DISTINCT provider) FILTER (WHERE provider IS NOT NULL), '{}')
+        FROM coins
+        WHERE name = $1 AND quote = $2 AND fixation_time >= $3 AND fixation_time <= $4
+    `, coin, quote, from, to, models.RejectDuplicate).Scan(&stats.Samples, &stats.Distinct, &stats.Quarantined,
+		&stats.Duplicates, &stats.Latency, &stats.Providers)
+	if err != nil {
+		return models.SampleStats{}, fmt.Errorf("%s; failed to get sample stats: %w", op, err)
+	}
+	return stats, nil
+}
+
+// GetLargestGaps возвращает limit самых длинных промежутков между соседними ценами валюты за период [from, to]
+func (s *Storage) GetLargestGaps(ctx context.Context, coin, quote string, from, to int64, limit int) ([]models.Gap, error) {
+	const op = "storage.pg.GetLargestGaps"
+	rows, err := s.DB.Query(ctx, `
+        SELECT prev, fixation_time, fixation_time - prev
+        FROM (
+            SELECT fixation_time, lag(fixation_time) OVER (ORDER BY fixation_time) AS prev
+            FROM coins
+            WHERE name = $1 AND quote = $2 AND fixation_time >= $3 AND fixation_time <= $4
+        ) t
+        WHERE fixation_time > prev
+        ORDER BY fixation_time - prev DESC, prev
+        LIMIT $5
+    `, coin, quote, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s; failed to get gaps: %w", op, err)
+	}
+	gaps, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Gap, error) {
+		var gap models.Gap
+		err := row.Scan(&gap.From, &gap.To, &gap.Duration)
+		return gap, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s; failed to read gaps: %w", op, err)
+	}
+	return gaps, nil
+}
@@ -0,0 +1,39 @@
+package pg
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"fmt"
+)
+
+// GetPriceWindow возвращает время последней цены валюты и медиану цен не раньше since
+func (s *Storage) GetPriceWindow(ctx context.Context, coin, quote string, since int64) (models.PriceWindow, error) {
+	const op = "storage.pg.GetPriceWindow"
+	var window models.PriceWindow
+	err := s.DB.QueryRow(ctx, `
+        SELECT (SELECT max(fixation_time) FROM coins WHERE name = $1 AND quote = $2),
+               percentile_cont(0.5) WITHIN GROUP (ORDER BY price::float8),
+               count(*)
+        FROM coins
+        WHERE name = $1 AND quote = $2 AND fixation_time >= $3
+    `, coin, quote, since).Scan(&window.Last, &window.Median, &window.Samples)
+	if err != nil {
+		return models.PriceWindow{}, fmt.Errorf("%s; failed to get price window: %w", op, err)
+	}
+	return window, nil
+}
+
+func (s *Storage) AddQuarantined(ctx context.Context, sample models.QuarantinedSample) (int64, error) {
+	const op = "storage.pg.AddQuarantined"
+	var id int64
+	err := s.DB.QueryRow(ctx, `
+        INSERT INTO quarantined_coins (name, quote, price, fixation_time, reason, detail, median, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id_quarantined
+    `, sample.Coin, sample.Quote, sample.Price, sample.Timestamp, sample.Reason, sample.Detail, sample.Median,
+		sample.CreatedAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s; failed to insert quarantined coin: %w", op, err)
+	}
+	return id, nil
+}
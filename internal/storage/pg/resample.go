@@ -0,0 +1,128 @@
+package pg
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// sample - известное значение ряда в момент at, полученное из цены со временем priceTime
+type sample struct {
+	at        int64
+	value     float64
+	priceTime int64
+}
+
+// GetSeries приводит цены валюты к сетке from, from+step, ..., to по правилам resample.
+// Значение в момент t агрегируется из цен интервала (t - step, t]; пустые интервалы заполняются согласно resample.Fill.
+func (s *Storage) GetSeries(ctx context.Context, coin, quote string, from, to int64, resample models.Resample) ([]models.SeriesPoint, error) {
+	const op = "storage.pg.GetSeries"
+	step := resample.Step
+	last := from + (to-from)/step*step
+
+	rows, err := s.DB.Query(ctx, `
+        SELECT $3 + (fixation_time - $3 + $5 - 1) / $5 * $5 AS t,
+               CASE $6
+                   WHEN 'first' THEN (array_agg(price::float8 ORDER BY fixation_time))[1]
+                   WHEN 'mean' THEN avg(price::float8)
+                   ELSE (array_agg(price::float8 ORDER BY fixation_time DESC))[1]
+               END,
+               max(fixation_time)
+        FROM coins
+        WHERE name = $1 AND quote = $2 AND fixation_time > $3 - $5 AND fixation_time <= $4
+        GROUP BY t
+        ORDER BY t
+    `, coin, quote, from, last, step, resample.Agg)
+	if err != nil {
+		return nil, fmt.Errorf("%s; failed to get buckets: %w", op, err)
+	}
+	buckets, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (sample, error) {
+		var b sample
+		err := row.Scan(&b.at, &b.value, &b.priceTime)
+		return b, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s; failed to read buckets: %w", op, err)
+	}
+
+	// Для заполнения нужны соседние цены за пределами сетки
+	var before, after *sample
+	if resample.Fill == models.FillPrevious || resample.Fill == models.FillLinear {
+		before, err = s.neighbour(ctx, `
+            SELECT price::float8, fixation_time FROM coins
+            WHERE name = $1 AND quote = $2 AND fixation_time <= $3
+            ORDER BY fixation_time DESC LIMIT 1
+        `, coin, quote, from-step)
+		if err != nil {
+			return nil, fmt.Errorf("%s; %w", op, err)
+		}
+	}
+	if resample.Fill == models.FillLinear {
+		after, err = s.neighbour(ctx, `
+            SELECT price::float8, fixation_time FROM coins
+            WHERE name = $1 AND quote = $2 AND fixation_time > $3
+            ORDER BY fixation_time LIMIT 1
+        `, coin, quote, last)
+		if err != nil {
+			return nil, fmt.Errorf("%s; %w", op, err)
+		}
+	}
+
+	return fill(from, last, step, resample.Fill, buckets, before, after), nil
+}
+
+func (s *Storage) neighbour(ctx context.Context, query, coin, quote string, at int64) (*sample, error) {
+	var n sample
+	err := s.DB.QueryRow(ctx, query, coin, quote, at).Scan(&n.value, &n.priceTime)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get neighbour price: %w", err)
+	}
+	n.at = n.priceTime
+	return &n, nil
+}
+
+// fill раскладывает значения непустых интервалов buckets по сетке from..last и заполняет пропуски.
+// before и after - ближайшие цены до и после сетки (для previous и linear), могут быть nil.
+func fill(from, last, step int64, method string, buckets []sample, before, after *sample) []models.SeriesPoint {
+	points := make([]models.SeriesPoint, 0, (last-from)/step+1)
+	prev := before
+	next := 0 // Индекс следующего непустого интервала
+	for t := from; t <= last; t += step {
+		point := models.SeriesPoint{Timestamp: t}
+		if next < len(buckets) && buckets[next].at == t {
+			b := buckets[next]
+			point.Value, point.PriceTime = &b.value, b.priceTime
+			prev = &buckets[next]
+			next++
+			points = append(points, point)
+			continue
+		}
+
+		switch method {
+		case models.FillPrevious:
+			if prev != nil {
+				point.Value, point.PriceTime, point.Filled = &prev.value, prev.priceTime, true
+			}
+		case models.FillZero:
+			zero := 0.0
+			point.Value, point.Filled = &zero, true
+		case models.FillLinear:
+			right := after
+			if next < len(buckets) {
+				right = &buckets[next]
+			}
+			if prev != nil && right != nil {
+				value := prev.value + (right.value-prev.value)*float64(t-prev.at)/float64(right.at-prev.at)
+				point.Value, point.Filled = &value, true
+			}
+		}
+		points = append(points, point)
+	}
+	return points
+}
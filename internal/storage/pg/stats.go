@@ -0,0 +1,42 @@
+package pg
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"fmt"
+)
+
+// GetPriceStats считает статистику цен валюты в котировке quote за период (from, to].
+// Если цен в периоде нет, возвращается storage.ErrPriceNotFound.
+func (s *Storage) GetPriceStats(ctx context.Context, coin, quote string, from, to int64) (models.CoinStats, error) {
+	const op = "storage.pg.GetPriceStats"
+	stats := models.CoinStats{Coin: coin, Quote: quote, From: from, To: to}
+	err := s.DB.QueryRow(ctx, `
+        WITH p AS (
+            SELECT price::float8 AS price, fixation_time,
+                   ln(price::float8 / lag(price::float8) OVER (ORDER BY fixation_time)) AS r
+            FROM coins
+            WHERE name = $1 AND quote = $2 AND fixation_time > $3 AND fixation_time <= $4 AND price > 0
+        )
+        SELECT count(*),
+               COALESCE((array_agg(price ORDER BY fixation_time))[1], 0),
+               COALESCE(min(fixation_time), 0),
+               COALESCE((array_agg(price ORDER BY fixation_time DESC))[1], 0),
+               COALESCE(max(fixation_time), 0),
+               COALESCE(min(price), 0),
+               COALESCE(max(price), 0),
+               COALESCE(avg(price), 0),
+               COALESCE(stddev_samp(price), 0),
+               COALESCE(stddev_samp(r), 0)
+        FROM p
+    `, coin, quote, from, to).Scan(&stats.Samples, &stats.First, &stats.FirstTimestamp, &stats.Last,
+		&stats.LastTimestamp, &stats.Min, &stats.Max, &stats.Mean, &stats.StdDev, &stats.ReturnsStdDev)
+	if err != nil {
+		return models.CoinStats{}, fmt.Errorf("%s; failed to get price stats: %w", op, err)
+	}
+	if stats.Samples == 0 {
+		return models.CoinStats{}, fmt.Errorf("%s; %s/%s in (%d, %d]: %w", op, coin, quote, from, to, storage.ErrPriceNotFound)
+	}
+	return stats, nil
+}
@@ -0,0 +1,150 @@
+package pg
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateTransaction сохраняет транзакцию, если check не вернул ошибку. check получает журнал портфеля
+// в хронологическом порядке; журнал читается и транзакция сохраняется в одной транзакции БД
+// под блокировкой портфеля, поэтому параллельные изменения журнала не проходят проверку мимо друг друга.
+func (s *Storage) CreateTransaction(ctx context.Context, tx models.Transaction, check func([]models.Transaction) error) (int64, error) {
+	const op = "storage.pg.CreateTransaction"
+	dbTx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s; failed to begin transaction: %w", op, err)
+	}
+	defer dbTx.Rollback(ctx)
+
+	txs, err := lockTransactions(ctx, dbTx, tx.PortfolioID)
+	if err != nil {
+		return 0, fmt.Errorf("%s; %w", op, err)
+	}
+	if err := check(txs); err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = dbTx.QueryRow(ctx, `
+        INSERT INTO transactions (id_portfolio, coin, type, quantity, price, price_time, fee, fixation_time, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        RETURNING id_transaction
+    `, tx.PortfolioID, tx.Coin, tx.Type, tx.Quantity, tx.Price, tx.PriceTime, tx.Fee, tx.Timestamp,
+		tx.CreatedAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s; failed to insert transaction: %w", op, err)
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("%s; failed to commit transaction: %w", op, err)
+	}
+	return id, nil
+}
+
+// GetTransactions возвращает транзакции портфеля не позже until в хронологическом порядке
+func (s *Storage) GetTransactions(ctx context.Context, portfolioID, until int64) ([]models.Transaction, error) {
+	const op = "storage.pg.GetTransactions"
+	rows, err := s.DB.Query(ctx, `
+        SELECT `+transactionColumns+`
+        FROM transactions
+        WHERE id_portfolio = $1 AND fixation_time <= $2
+        ORDER BY fixation_time, id_transaction
+    `, portfolioID, until)
+	if err != nil {
+		return nil, fmt.Errorf("%s; failed to get transactions: %w", op, err)
+	}
+	txs, err := collectTransactions(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s; %w", op, err)
+	}
+	return txs, nil
+}
+
+// DeleteTransaction удаляет транзакцию, если check не вернул ошибку. check получает весь журнал портфеля
+// (вместе с удаляемой транзакцией); проверка и удаление выполняются под блокировкой портфеля, как в CreateTransaction.
+func (s *Storage) DeleteTransaction(ctx context.Context, portfolioID, id int64, check func([]models.Transaction) error) error {
+	const op = "storage.pg.DeleteTransaction"
+	dbTx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s; failed to begin transaction: %w", op, err)
+	}
+	defer dbTx.Rollback(ctx)
+
+	txs, err := lockTransactions(ctx, dbTx, portfolioID)
+	if err != nil {
+		return fmt.Errorf("%s; %w", op, err)
+	}
+	if err := check(txs); err != nil {
+		return err
+	}
+
+	tag, err := dbTx.Exec(ctx, `
+        DELETE FROM transactions
+        WHERE id_portfolio = $1 AND id_transaction = $2
+    `, portfolioID, id)
+	if err != nil {
+		return fmt.Errorf("%s; failed to delete transaction: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s; %w", op, storage.ErrTransactionNotFound)
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s; failed to commit transaction: %w", op, err)
+	}
+	return nil
+}
+
+const transactionColumns = `id_transaction, id_portfolio, coin, type, quantity, price, price_time, fee, fixation_time, created_at`
+
+// lockTransactions блокирует портфель (SELECT ... FOR UPDATE) до конца транзакции dbTx и возвращает весь его журнал.
+// Если портфеля нет, возвращает storage.ErrPortfolioNotFound.
+func lockTransactions(ctx context.Context, dbTx pgx.Tx, portfolioID int64) ([]models.Transaction, error) {
+	var locked int64
+	err := dbTx.QueryRow(ctx, `
+        SELECT id_portfolio
+        FROM portfolios
+        WHERE id_portfolio = $1
+        FOR UPDATE
+    `, portfolioID).Scan(&locked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrPortfolioNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock portfolio: %w", err)
+	}
+
+	rows, err := dbTx.Query(ctx, `
+        SELECT `+transactionColumns+`
+        FROM transactions
+        WHERE id_portfolio = $1
+        ORDER BY fixation_time, id_transaction
+    `, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+	return collectTransactions(rows)
+}
+
+func collectTransactions(rows pgx.Rows) ([]models.Transaction, error) {
+	defer rows.Close()
+
+	txs := make([]models.Transaction, 0)
+	for rows.Next() {
+		var tx models.Transaction
+		if err := rows.Scan(&tx.ID, &tx.PortfolioID, &tx.Coin, &tx.Type, &tx.Quantity, &tx.Price, &tx.PriceTime,
+			&tx.Fee, &tx.Timestamp, &tx.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		txs = append(txs, tx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read transactions: %w", err)
+	}
+	return txs, nil
+}
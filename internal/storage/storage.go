@@ -0,0 +1,17 @@
+package storage
+
+import "errors"
+
+var (
+	ErrAlertNotFound  = errors.New("alert not found")
+	ErrFXRateNotFound = errors.New("fx rate not found")
+	ErrPriceNotFound  = errors.New("price not found")
+	ErrPriceExists    = errors.New("price already exists")
+
+	ErrPortfolioNotFound = errors.New("portfolio not found")
+	ErrHoldingNotFound   = errors.New("holding not found")
+
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrBasketNotFound      = errors.New("basket not found")
+	ErrJobNotFound         = errors.New("job not found")
+)
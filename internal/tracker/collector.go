@@ -0,0 +1,355 @@
+package tracker
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"crypto_tracker/internal/storage"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	N = 10 // Сколько секунд ждать перед следующим считыванием валюты
+)
+
+// Provider - имя внешнего API, которое сохраняется как источник цен
+const Provider = "mobula"
+
+// PendingInterval - как часто повторяется проверка валюты, добавленной без проверки (см. WatchPending)
+var PendingInterval = time.Minute
+
+var (
+	ErrInvalidCoin    = errors.New("invalid coin")
+	ErrAlreadyTracked = errors.New("coin is already being tracked")
+)
+
+type CoinSaver interface {
+	AddCoin(ctx context.Context, coin models.Coin) (int64, error)
+}
+
+// Observer получает каждую цену, успешно сохранённую коллектором
+type Observer interface {
+	OnPrice(ctx context.Context, coin models.Coin)
+}
+
+// Collector собирает цены отслеживаемых криптовалют из внешнего API
+type Collector struct {
+	log       *slog.Logger
+	apiURL    string
+	apiKey    string
+	storage   CoinSaver
+	validator *Validator
+	restart   RestartPolicy
+	observers []Observer
+
+	mu       sync.Mutex
+	statuses map[string]*models.CollectorStatus // Состояние сбора по ключу Key
+	names    map[string]string                  // Название валюты у провайдера по названию, под которым её добавили, и по тикеру
+}
+
+func NewCollector(log *slog.Logger, apiURL, apiKey string, storage CoinSaver, validator *Validator,
+	restart RestartPolicy, observers ...Observer) *Collector {
+	return &Collector{
+		log:       log,
+		apiURL:    apiURL,
+		apiKey:    apiKey,
+		storage:   storage,
+		validator: validator,
+		restart:   restart,
+		observers: observers,
+		statuses:  make(map[string]*models.CollectorStatus),
+		names:     make(map[string]string),
+	}
+}
+
+// Name возвращает название, под которым сохраняются цены валюты coin: название из последнего ответа провайдера
+// или coin, если провайдер ещё не отвечал. coin может быть и тикером (BTC) валюты, по которой уже приходил ответ.
+func (c *Collector) Name(coin string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if name, ok := c.names[coin]; ok {
+		return name
+	}
+	return coin
+}
+
+// canonicalName запоминает название валюты coin и её тикер из ответа провайдера и возвращает название.
+// Цены из сбора, дозагрузки пропусков и загрузки истории сохраняются под одним названием.
+func (c *Collector) canonicalName(coin, name, symbol string) string {
+	if name == "" {
+		return c.Name(coin)
+	}
+	c.mu.Lock()
+	c.names[coin] = name
+	if symbol != "" {
+		c.names[strings.ToUpper(symbol)] = name
+	}
+	c.mu.Unlock()
+	return name
+}
+
+// ValidateCoin проверяет, существует ли валюта во внешнем API. Возвращает ErrInvalidCoin, если провайдер
+// не знает валюту, или *ProviderError, если проверить валюту не удалось.
+func (c *Collector) ValidateCoin(ctx context.Context, coin string) error {
+	var metadata json.RawMessage
+	err := c.request(ctx, c.endpoint("/api/1/metadata", url.Values{"asset": {coin}}), &metadata)
+	if errors.Is(err, ErrProviderNotFound) {
+		return fmt.Errorf("%w: %w", ErrInvalidCoin, err)
+	}
+	return err
+}
+
+// Watch проверяет валюту во внешнем API, добавляет пару coin/quote в список отслеживаемых
+// и запускает горутину сбора цен
+func (c *Collector) Watch(ctx context.Context, coin, quote string) error {
+	// Проверяем, существует ли валюта через внешний API
+	if err := c.ValidateCoin(ctx, coin); err != nil {
+		return err
+	}
+	if err := c.track(coin, quote); err != nil {
+		return err
+	}
+
+	// Запускаем горутину для сбора данных
+	go c.Start(context.WithoutCancel(ctx), coin, quote)
+
+	return nil
+}
+
+// WatchPending добавляет пару coin/quote в список отслеживаемых без проверки валюты: проверка повторяется
+// в фоне раз в PendingInterval, и после успешной проверки начинается сбор цен. Если провайдер не знает
+// валюту, пара удаляется из отслеживаемых.
+func (c *Collector) WatchPending(ctx context.Context, coin, quote string) error {
+	if err := c.track(coin, quote); err != nil {
+		return err
+	}
+	c.update(coin, quote, func(status *models.CollectorStatus) {
+		status.State = models.CollectorPending
+		status.StartedAt = time.Now().UnixMilli()
+	})
+
+	go c.recheck(context.WithoutCancel(ctx), coin, quote, c.register(coin, quote))
+	return nil
+}
+
+// track добавляет пару в мапу отслеживаемых, если её там ещё нет
+func (c *Collector) track(coin, quote string) error {
+	key := Key(coin, quote)
+
+	// Проверяем, не отслеживается ли уже эта криптовалюта
+	TrackedMutex.Lock()
+	defer TrackedMutex.Unlock()
+	if _, exists := TrackedCoins[key]; exists {
+		return ErrAlreadyTracked
+	}
+
+	// Добавляем криптовалюту в мапу отслеживаемых
+	TrackedCoins[key] = true
+	return nil
+}
+
+// register создаёт канал остановки пары и сохраняет его в глобальной мапе
+func (c *Collector) register(coin, quote string) chan struct{} {
+	stopChan := make(chan struct{})
+	StopMutex.Lock()
+	StopChannels[Key(coin, quote)] = stopChan
+	StopMutex.Unlock()
+	return stopChan
+}
+
+// release удаляет пару из отслеживаемых и её состояние
+func (c *Collector) release(coin, quote string) {
+	TrackedMutex.Lock()
+	delete(TrackedCoins, Key(coin, quote))
+	TrackedMutex.Unlock()
+	c.removeStatus(coin, quote)
+}
+
+// recheck повторяет проверку валюты до успеха, отказа провайдера в валюте или сигнала остановки
+func (c *Collector) recheck(ctx context.Context, coin, quote string, stopChan chan struct{}) {
+	ticker := time.NewTicker(PendingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopChan:
+			c.log.Info("Stopped pending validation for coin", "coin", coin, "quote", quote)
+			c.release(coin, quote)
+			return
+		case <-ticker.C:
+			err := c.ValidateCoin(ctx, coin)
+			if errors.Is(err, ErrInvalidCoin) {
+				c.log.Warn("Pending coin rejected by provider", "coin", coin, "quote", quote)
+				StopMutex.Lock()
+				if StopChannels[Key(coin, quote)] == stopChan {
+					delete(StopChannels, Key(coin, quote))
+				}
+				StopMutex.Unlock()
+				c.release(coin, quote)
+				return
+			}
+			if err != nil {
+				c.log.Warn("Pending coin validation failed", "coin", coin, "quote", quote, "kind", ErrorKind(err),
+					"error", err)
+				c.recordError(coin, quote, err)
+				continue
+			}
+
+			ticker.Stop()
+			c.log.Info("Pending coin validated", "coin", coin, "quote", quote)
+			c.collect(ctx, coin, quote, stopChan)
+			return
+		}
+	}
+}
+
+// Start собирает цены валюты в валюте котировки quote до получения сигнала остановки
+func (c *Collector) Start(ctx context.Context, coin, quote string) {
+	c.collect(ctx, coin, quote, c.register(coin, quote))
+}
+
+// run собирает цены пары раз в N секунд до закрытия stopChan и отмечает в saved, что цена была сохранена.
+// Запускается супервизором (см. collect), который перезапускает run после паники.
+func (c *Collector) run(ctx context.Context, coin, quote string, stopChan chan struct{}, saved *bool) {
+	ticker := time.NewTicker(time.Duration(N) * time.Second) // Интервал сбора данных
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopChan:
+			// Останавливаем горутину, если получен сигнал
+			c.log.Info("Stopped price collection for coin", "coin", coin, "quote", quote)
+			return
+		case <-ticker.C:
+			ctx = context.WithoutCancel(ctx)
+			info, err := c.fetchPriceFromAPI(ctx, coin, quote)
+			if err != nil {
+				c.log.Warn("Failed to fetch price", "coin", coin, "quote", quote, "kind", ErrorKind(err), "error", err)
+				c.recordError(coin, quote, err)
+				continue
+			}
+
+			// Проверяем цену перед сохранением, отклонённые попадают в карантин
+			ok, err := c.validator.Check(ctx, info)
+			if err != nil {
+				c.log.Error("Failed to validate price", "coin", coin, "quote", quote, "error", err)
+				c.recordError(coin, quote, err)
+				continue
+			}
+			if !ok {
+				continue
+			}
+
+			// Сохраняем цену в базу данных
+			id, err := c.storage.AddCoin(ctx, info)
+			if errors.Is(err, storage.ErrPriceExists) {
+				// Цена на это время уже сохранена: учитываем повтор в карантине
+				c.log.Debug("Price already saved", "coin", coin, "quote", quote, "timestamp", info.Timestamp)
+				if err := c.validator.Duplicate(ctx, info); err != nil {
+					c.log.Error("Failed to quarantine duplicate price", "coin", coin, "quote", quote, "error", err)
+				}
+				continue
+			}
+			if err != nil {
+				c.log.Error("Failed to save price", "coin", coin, "quote", quote, "error", err)
+				c.recordError(coin, quote, err)
+				continue
+			}
+			info.ID = id
+			c.recordSuccess(coin, quote)
+			*saved = true
+
+			// Оповещаем подписчиков о новой цене (сведения об источнике, как и при чтении, не передаются)
+			info.Provenance = nil
+			for _, observer := range c.observers {
+				observer.OnPrice(ctx, info)
+			}
+		}
+	}
+}
+
+// FetchHistory запрашивает у провайдера цены валюты за период [from, to] (timestamp в миллисекундах)
+func (c *Collector) FetchHistory(ctx context.Context, coin, quote string, from, to int64) ([]models.Coin, error) {
+	params := historyParams(coin, quote, from)
+	params.Set("to", strconv.FormatInt(to, 10))
+	var responseAPI historyResponse
+	if err := c.request(ctx, c.endpoint("/api/1/market/history", params), &responseAPI); err != nil {
+		return nil, err
+	}
+
+	name := c.canonicalName(coin, responseAPI.Data.Name, responseAPI.Data.Symbol)
+	prices := make([]models.Coin, 0, len(responseAPI.Data.PriceHistory))
+	for _, point := range responseAPI.Data.PriceHistory {
+		timestamp, price := int64(point[0]), point[1]
+		if timestamp < from || timestamp > to || price <= 0 {
+			continue
+		}
+		providerTime := point[0]
+		prices = append(prices, models.Coin{
+			Name:       name,
+			Quote:      quote,
+			Price:      price,
+			Timestamp:  timestamp,
+			Provenance: &models.Provenance{Provider: Provider, ProviderTime: &providerTime},
+		})
+	}
+	return prices, nil
+}
+
+// historyParams возвращает параметры запроса market/history для валюты coin в котировке quote с момента from
+func historyParams(coin, quote string, from int64) url.Values {
+	params := url.Values{"asset": {coin}, "from": {strconv.FormatInt(from, 10)}}
+	// Без параметра провайдер возвращает цены в USD
+	if quote != models.DefaultQuote {
+		params.Set("quote", quote)
+	}
+	return params
+}
+
+// historyResponse - ответ провайдера market/history: пары [timestamp в миллисекундах, цена]
+type historyResponse struct {
+	Data struct {
+		Name         string       `json:"name"`
+		Symbol       string       `json:"symbol"`
+		PriceHistory [][2]float64 `json:"price_history"`
+	} `json:"data"`
+}
+
+// fetchPriceFromAPI возвращает последнюю цену валюты из истории провайдера за последние сутки.
+// Ошибки провайдера, в том числе пустая история, возвращаются как *ProviderError.
+func (c *Collector) fetchPriceFromAPI(ctx context.Context, coin, quote string) (models.Coin, error) {
+	currentTimeMillis := time.Now().Add(-24*time.Hour).Unix() * 1000
+
+	var responseAPI historyResponse
+	err := c.request(ctx, c.endpoint("/api/1/market/history", historyParams(coin, quote, currentTimeMillis)),
+		&responseAPI)
+	if err != nil {
+		return models.Coin{}, err
+	}
+
+	history := responseAPI.Data.PriceHistory
+	if len(history) == 0 {
+		return models.Coin{}, &ProviderError{Kind: ErrMalformed, Status: http.StatusOK,
+			Err: fmt.Errorf("empty price history for %s", coin)}
+	}
+	last := history[len(history)-1]
+	c.log.Debug("Price fetched", "coin", coin, "quote", quote, "price", last[1], "timestamp", int64(last[0]))
+
+	name := c.canonicalName(coin, responseAPI.Data.Name, responseAPI.Data.Symbol)
+	ingestedAt := time.Now().UnixMilli()
+	return models.Coin{
+		Name:       name,
+		Quote:      quote,
+		Price:      last[1],
+		Timestamp:  int64(last[0]),
+		Provenance: &models.Provenance{Provider: Provider, ProviderTime: &last[0], IngestedAt: &ingestedAt},
+	}, nil
+}
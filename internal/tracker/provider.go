@@ -0,0 +1,132 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Виды ошибок провайдера цен
+var (
+	ErrProviderNotFound = errors.New("asset not found")
+	ErrRateLimited      = errors.New("rate limited")
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrUpstream         = errors.New("upstream error")
+	ErrMalformed        = errors.New("malformed payload")
+)
+
+// errorKinds - коды видов ошибок в статусе коллектора и логах
+var errorKinds = []struct {
+	err  error
+	kind string
+}{
+	{ErrProviderNotFound, "not_found"},
+	{ErrRateLimited, "rate_limited"},
+	{ErrUnauthorized, "unauthorized"},
+	{ErrUpstream, "upstream"},
+	{ErrMalformed, "malformed"},
+}
+
+// ProviderError - ошибка запроса к провайдеру цен. Kind - один из ErrProviderNotFound, ErrRateLimited,
+// ErrUnauthorized, ErrUpstream, ErrMalformed; errors.Is(err, Kind) выполняется.
+type ProviderError struct {
+	Kind       error
+	Status     int           // HTTP-статус ответа, 0 если ответа не было
+	RetryAfter time.Duration // Из заголовка Retry-After, 0 если его нет
+	Err        error         // Подробности
+}
+
+func (e *ProviderError) Error() string {
+	msg := e.Kind.Error()
+	if e.Status != 0 {
+		msg += fmt.Sprintf(" (status %d)", e.Status)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ProviderError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// ErrorKind возвращает код вида ошибки провайдера или "internal" для остальных ошибок
+func ErrorKind(err error) string {
+	for _, k := range errorKinds {
+		if errors.Is(err, k.err) {
+			return k.kind
+		}
+	}
+	return "internal"
+}
+
+// endpoint возвращает адрес метода провайдера path с параметрами запроса и ключом API
+func (c *Collector) endpoint(path string, params url.Values) string {
+	params.Set("api_key", c.apiKey)
+	return c.apiURL + path + "?" + params.Encode()
+}
+
+// request выполняет GET-запрос к провайдеру и декодирует ответ в dst.
+// Ошибки ответа и декодирования возвращаются как *ProviderError.
+func (c *Collector) request(ctx context.Context, rawURL string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// В тексте *url.Error есть адрес запроса вместе с api_key
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return &ProviderError{Kind: ErrUpstream, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return &ProviderError{Kind: ErrMalformed, Status: resp.StatusCode, Err: err}
+	}
+	return nil
+}
+
+// statusError приводит ответ провайдера со статусом не 200 к *ProviderError
+func statusError(resp *http.Response) error {
+	e := &ProviderError{Status: resp.StatusCode}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		e.Kind = ErrProviderNotFound
+	case resp.StatusCode == http.StatusTooManyRequests:
+		e.Kind = ErrRateLimited
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			e.RetryAfter = time.Duration(seconds) * time.Second
+		}
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		e.Kind = ErrUnauthorized
+	default:
+		e.Kind = ErrUpstream
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if text := strings.TrimSpace(string(body)); text != "" {
+		e.Err = errors.New(text)
+	}
+	return e
+}
@@ -0,0 +1,68 @@
+package tracker
+
+import (
+	"crypto_tracker/internal/models"
+	"sort"
+	"time"
+)
+
+// Statuses возвращает состояние сбора цен всех запущенных пар, упорядоченное по валюте и котировке
+func (c *Collector) Statuses() []models.CollectorStatus {
+	c.mu.Lock()
+	statuses := make([]models.CollectorStatus, 0, len(c.statuses))
+	for _, status := range c.statuses {
+		statuses = append(statuses, *status)
+	}
+	c.mu.Unlock()
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return Key(statuses[i].Coin, statuses[i].Quote) < Key(statuses[j].Coin, statuses[j].Quote)
+	})
+	return statuses
+}
+
+// Status возвращает состояние сбора цен пары coin/quote
+func (c *Collector) Status(coin, quote string) (models.CollectorStatus, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status, ok := c.statuses[Key(coin, quote)]
+	if !ok {
+		return models.CollectorStatus{}, false
+	}
+	return *status, true
+}
+
+// update изменяет состояние пары под мьютексом
+func (c *Collector) update(coin, quote string, change func(status *models.CollectorStatus)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := Key(coin, quote)
+	status, ok := c.statuses[key]
+	if !ok {
+		status = &models.CollectorStatus{Coin: coin, Quote: quote}
+		c.statuses[key] = status
+	}
+	change(status)
+}
+
+func (c *Collector) recordSuccess(coin, quote string) {
+	c.update(coin, quote, func(status *models.CollectorStatus) {
+		status.LastSuccess = time.Now().UnixMilli()
+		status.ConsecutiveErrors = 0
+	})
+}
+
+func (c *Collector) recordError(coin, quote string, err error) {
+	c.update(coin, quote, func(status *models.CollectorStatus) {
+		status.LastError = err.Error()
+		status.LastErrorKind = ErrorKind(err)
+		status.LastErrorAt = time.Now().UnixMilli()
+		status.ConsecutiveErrors++
+	})
+}
+
+func (c *Collector) removeStatus(coin, quote string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.statuses, Key(coin, quote))
+}
@@ -0,0 +1,82 @@
+package tracker
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// MaxBackoff - максимальная пауза перед перезапуском сбора цен после паники
+var MaxBackoff = 5 * time.Minute
+
+// RestartPolicy - правила перезапуска сбора цен пары после паники
+type RestartPolicy struct {
+	MaxCrashes int           // После стольких паник подряд без сохранённой цены сбор переходит в состояние failed
+	Backoff    time.Duration // Пауза перед первым перезапуском, удваивается после каждой паники (не больше MaxBackoff)
+}
+
+// collect собирает цены пары до закрытия stopChan, перезапуская сбор после паники.
+// В состоянии failed горутина ждёт сигнала остановки, чтобы пара оставалась видна в статусе.
+func (c *Collector) collect(ctx context.Context, coin, quote string, stopChan chan struct{}) {
+	// Удаляем пару из отслеживаемых при завершении горутины
+	defer c.release(coin, quote)
+
+	backoff := c.restart.Backoff
+	crashes := 0
+	for {
+		c.update(coin, quote, func(status *models.CollectorStatus) {
+			status.State = models.CollectorRunning
+			status.StartedAt = time.Now().UnixMilli()
+		})
+
+		saved, err := c.safeRun(ctx, coin, quote, stopChan)
+		if err == nil {
+			return
+		}
+		if saved {
+			crashes, backoff = 0, c.restart.Backoff
+		}
+		crashes++
+
+		state := models.CollectorRestarting
+		if crashes >= c.restart.MaxCrashes {
+			state = models.CollectorFailed
+		}
+		c.update(coin, quote, func(status *models.CollectorStatus) {
+			status.State = state
+			status.Crashes++
+			status.LastCrash = err.Error()
+			status.LastCrashAt = time.Now().UnixMilli()
+		})
+
+		if state == models.CollectorFailed {
+			c.log.Error("Price collector failed", "coin", coin, "quote", quote, "crashes", crashes, "error", err)
+			<-stopChan
+			c.log.Info("Stopped price collection for coin", "coin", coin, "quote", quote)
+			return
+		}
+		c.log.Warn("Restarting price collector", "coin", coin, "quote", quote, "crashes", crashes, "backoff", backoff)
+		select {
+		case <-stopChan:
+			c.log.Info("Stopped price collection for coin", "coin", coin, "quote", quote)
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, MaxBackoff)
+	}
+}
+
+// safeRun запускает run и возвращает ошибку, если он завершился паникой
+func (c *Collector) safeRun(ctx context.Context, coin, quote string, stopChan chan struct{}) (saved bool, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			c.log.Error("Price collector panicked", "coin", coin, "quote", quote, "panic", p,
+				"stack", string(debug.Stack()))
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	c.run(ctx, coin, quote, stopChan, &saved)
+	return saved, nil
+}
@@ -1,6 +1,12 @@
 package tracker
 
-import "sync"
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Ключи мап имеют вид "<валюта>/<валюта котировки>", см. Key
 
 var (
 	TrackedCoins = make(map[string]bool) // Глобальная мапа для отслеживаемых криптовалют
@@ -9,3 +15,32 @@ var (
 	StopChannels = make(map[string]chan struct{}) // Глобальная мапа для каналов остановки
 	StopMutex    sync.Mutex                       // Мьютекс для синхронизации доступа к мапе
 )
+
+// Key возвращает ключ пары валюта/котировка в глобальных мапах
+func Key(coin, quote string) string {
+	return coin + "/" + quote
+}
+
+// Pair - отслеживаемая пара валюта/котировка
+type Pair struct {
+	Coin  string
+	Quote string
+}
+
+// Tracked возвращает отслеживаемые пары, упорядоченные по ключу
+func Tracked() []Pair {
+	TrackedMutex.Lock()
+	keys := make([]string, 0, len(TrackedCoins))
+	for key := range TrackedCoins {
+		keys = append(keys, key)
+	}
+	TrackedMutex.Unlock()
+	sort.Strings(keys)
+
+	pairs := make([]Pair, 0, len(keys))
+	for _, key := range keys {
+		i := strings.LastIndex(key, "/")
+		pairs = append(pairs, Pair{Coin: key[:i], Quote: key[i+1:]})
+	}
+	return pairs
+}
@@ -0,0 +1,122 @@
+package tracker
+
+import (
+	"context"
+	"crypto_tracker/internal/models"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+)
+
+// MinSamples - сколько цен должно быть в окне, чтобы сравнивать новую цену с медианой
+var MinSamples = 3
+
+// errUnchanged - время цены совпадает со временем последней сохранённой: провайдер ещё не обновил цену
+var errUnchanged = errors.New("price is not newer than the last stored one")
+
+type ValidatorStorage interface {
+	GetPriceWindow(ctx context.Context, coin, quote string, since int64) (models.PriceWindow, error)
+	AddQuarantined(ctx context.Context, sample models.QuarantinedSample) (int64, error)
+}
+
+// Validator проверяет цену перед сохранением и помещает отклонённые цены в карантин (таблица quarantined_coins).
+// Проверяются только цены, полученные коллектором. Цены из дозаполнения пропусков и загрузки истории
+// заведомо старше последней сохранённой, поэтому проверка времени к ним неприменима; неположительные цены
+// и цены вне запрошенного периода отбрасывает FetchHistory, а повторы - ограничение уникальности в coins.
+type Validator struct {
+	log          *slog.Logger
+	storage      ValidatorStorage
+	spikePercent float64       // Допустимое отклонение от скользящей медианы в процентах
+	window       time.Duration // Окно скользящей медианы перед временем цены
+	skew         time.Duration // Допустимое опережение часов провайдера
+}
+
+func NewValidator(log *slog.Logger, storage ValidatorStorage, spikePercent float64, window, skew time.Duration) *Validator {
+	return &Validator{
+		log:          log,
+		storage:      storage,
+		spikePercent: spikePercent,
+		window:       window,
+		skew:         skew,
+	}
+}
+
+// Check возвращает true, если цену можно сохранить. Отклонённая цена сохраняется в карантин.
+// Цена с тем же временем, что и последняя сохранённая, не сохраняется и не попадает в карантин.
+// Если в окне меньше MinSamples цен, скачок не проверяется: так после резкого, но настоящего
+// изменения цены сбор возобновляется, как только старые цены выходят из окна.
+func (v *Validator) Check(ctx context.Context, coin models.Coin) (bool, error) {
+	rejected, err := v.validate(ctx, coin)
+	if errors.Is(err, errUnchanged) {
+		v.log.Debug("Price unchanged", "coin", coin.Name, "quote", coin.Quote, "timestamp", coin.Timestamp)
+		return false, nil
+	}
+	if err != nil || rejected == nil {
+		return err == nil, err
+	}
+
+	rejected.CreatedAt = time.Now().UnixMilli()
+	if _, err := v.storage.AddQuarantined(ctx, *rejected); err != nil {
+		return false, err
+	}
+	v.log.Warn("Price quarantined", "coin", coin.Name, "quote", coin.Quote, "price", coin.Price,
+		"timestamp", coin.Timestamp, "reason", rejected.Reason, "detail", rejected.Detail)
+	return false, nil
+}
+
+// Duplicate помещает в карантин цену, которую не удалось сохранить: цена валюты на это время уже есть в coins.
+// Такие цены учитываются в отчёте о качестве данных как повторы.
+func (v *Validator) Duplicate(ctx context.Context, coin models.Coin) error {
+	_, err := v.storage.AddQuarantined(ctx, models.QuarantinedSample{
+		Coin:      coin.Name,
+		Quote:     coin.Quote,
+		Price:     coin.Price,
+		Timestamp: coin.Timestamp,
+		Reason:    models.RejectDuplicate,
+		CreatedAt: time.Now().UnixMilli(),
+	})
+	return err
+}
+
+// validate возвращает отклонённую цену или nil, если цена прошла проверку
+func (v *Validator) validate(ctx context.Context, coin models.Coin) (*models.QuarantinedSample, error) {
+	reject := func(reason, detail string) *models.QuarantinedSample {
+		return &models.QuarantinedSample{
+			Coin:      coin.Name,
+			Quote:     coin.Quote,
+			Price:     coin.Price,
+			Timestamp: coin.Timestamp,
+			Reason:    reason,
+			Detail:    detail,
+		}
+	}
+
+	if coin.Price <= 0 {
+		return reject(models.RejectNonPositive, ""), nil
+	}
+	if now := time.Now(); coin.Timestamp > now.Add(v.skew).UnixMilli() {
+		return reject(models.RejectFuture, fmt.Sprintf("%d ms ahead of now", coin.Timestamp-now.UnixMilli())), nil
+	}
+
+	window, err := v.storage.GetPriceWindow(ctx, coin.Name, coin.Quote, coin.Timestamp-v.window.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	if window.Last != nil && coin.Timestamp == *window.Last {
+		return nil, errUnchanged
+	}
+	if window.Last != nil && coin.Timestamp < *window.Last {
+		return reject(models.RejectStale, fmt.Sprintf("last stored sample at %d", *window.Last)), nil
+	}
+	if window.Median != nil && *window.Median > 0 && window.Samples >= MinSamples {
+		deviation := math.Abs(coin.Price-*window.Median) / *window.Median * 100
+		if deviation > v.spikePercent {
+			sample := reject(models.RejectSpike, fmt.Sprintf("%.2f%% from median of %d samples", deviation, window.Samples))
+			sample.Median = window.Median
+			return sample, nil
+		}
+	}
+	return nil, nil
+}
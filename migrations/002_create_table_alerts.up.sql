@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS alerts (
+    id_alert serial PRIMARY KEY,
+	coin varchar(256) NOT NULL,
+	condition varchar(16) NOT NULL,
+	price numeric(22,12),
+	change_percent numeric(10,4),
+	change_window varchar(32),
+	triggered boolean NOT NULL DEFAULT false,
+	created_at bigint NOT NULL
+);
+
+CREATE INDEX idx_alerts_coin ON alerts (coin);
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS alert_events;
+
+ALTER TABLE alerts ADD COLUMN triggered boolean NOT NULL DEFAULT false;
+
+UPDATE alerts SET triggered = true WHERE state = 'firing';
+
+ALTER TABLE alerts
+    DROP COLUMN state,
+    DROP COLUMN hysteresis_percent,
+    DROP COLUMN cooldown,
+    DROP COLUMN last_notified_at;
@@ -0,0 +1,22 @@
+ALTER TABLE alerts
+    ADD COLUMN state varchar(16) NOT NULL DEFAULT 'ok',
+    ADD COLUMN hysteresis_percent numeric(10,4) NOT NULL DEFAULT 0,
+    ADD COLUMN cooldown varchar(32),
+    ADD COLUMN last_notified_at bigint;
+
+UPDATE alerts SET state = 'firing' WHERE triggered;
+
+ALTER TABLE alerts DROP COLUMN triggered;
+
+CREATE TABLE IF NOT EXISTS alert_events (
+    id_event serial PRIMARY KEY,
+	id_alert integer NOT NULL REFERENCES alerts (id_alert) ON DELETE CASCADE,
+	from_state varchar(16) NOT NULL,
+	to_state varchar(16) NOT NULL,
+	price numeric(22,12) NOT NULL,
+	fixation_time bigint NOT NULL,
+	notified boolean NOT NULL,
+	created_at bigint NOT NULL
+);
+
+CREATE INDEX idx_alert_events_alert ON alert_events (id_alert, created_at);
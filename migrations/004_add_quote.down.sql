@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_alerts_coin_quote;
+ALTER TABLE alerts DROP COLUMN quote;
+CREATE INDEX idx_alerts_coin ON alerts (coin);
+
+DROP INDEX IF EXISTS idx_coin_quote_timestamp;
+ALTER TABLE coins DROP COLUMN quote;
+CREATE INDEX idx_coin_timestamp ON coins (name, fixation_time);
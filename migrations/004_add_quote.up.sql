@@ -0,0 +1,9 @@
+ALTER TABLE coins ADD COLUMN quote varchar(16) NOT NULL DEFAULT 'USD';
+
+DROP INDEX IF EXISTS idx_coin_timestamp;
+CREATE INDEX idx_coin_quote_timestamp ON coins (name, quote, fixation_time);
+
+ALTER TABLE alerts ADD COLUMN quote varchar(16) NOT NULL DEFAULT 'USD';
+
+DROP INDEX IF EXISTS idx_alerts_coin;
+CREATE INDEX idx_alerts_coin_quote ON alerts (coin, quote);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS fx_rates (
+    id_rate serial PRIMARY KEY,
+	base varchar(16) NOT NULL,
+	quote varchar(16) NOT NULL,
+	rate numeric(22,12) NOT NULL,
+	fixation_time bigint NOT NULL,
+	UNIQUE (base, quote, fixation_time)
+);
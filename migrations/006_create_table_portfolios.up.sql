@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS portfolios (
+    id_portfolio serial PRIMARY KEY,
+	name varchar(256) NOT NULL,
+	created_at bigint NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS holdings (
+    id_holding serial PRIMARY KEY,
+	id_portfolio integer NOT NULL REFERENCES portfolios (id_portfolio) ON DELETE CASCADE,
+	coin varchar(256) NOT NULL,
+	quantity numeric(30,12) NOT NULL,
+	UNIQUE (id_portfolio, coin)
+);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS transactions (
+    id_transaction serial PRIMARY KEY,
+	id_portfolio integer NOT NULL REFERENCES portfolios (id_portfolio) ON DELETE CASCADE,
+	coin varchar(256) NOT NULL,
+	type varchar(16) NOT NULL,
+	quantity numeric(30,12) NOT NULL,
+	price numeric(22,12) NOT NULL,
+	price_time bigint,
+	fee numeric(22,12) NOT NULL DEFAULT 0,
+	fixation_time bigint NOT NULL,
+	created_at bigint NOT NULL
+);
+
+CREATE INDEX idx_transactions_portfolio_time ON transactions (id_portfolio, fixation_time);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS basket_components;
+DROP TABLE IF EXISTS baskets;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS baskets (
+    id_basket serial PRIMARY KEY,
+	name varchar(256) NOT NULL,
+	quote varchar(16) NOT NULL DEFAULT 'USD',
+	base_time bigint NOT NULL,
+	rebalance varchar(16) NOT NULL DEFAULT 'none',
+	created_at bigint NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS basket_components (
+    id_component serial PRIMARY KEY,
+	id_basket integer NOT NULL REFERENCES baskets (id_basket) ON DELETE CASCADE,
+	coin varchar(256) NOT NULL,
+	weight numeric(22,12) NOT NULL,
+	UNIQUE (id_basket, coin)
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS backfill_jobs;
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS backfill_jobs (
+    id_job serial PRIMARY KEY,
+	coin varchar(256) NOT NULL,
+	quote varchar(16) NOT NULL DEFAULT 'USD',
+	from_time bigint NOT NULL,
+	to_time bigint NOT NULL,
+	cursor_time bigint NOT NULL,
+	status varchar(16) NOT NULL DEFAULT 'queued',
+	inserted bigint NOT NULL DEFAULT 0,
+	error text NOT NULL DEFAULT '',
+	created_at bigint NOT NULL,
+	updated_at bigint NOT NULL
+);
+
+CREATE INDEX idx_backfill_jobs_status ON backfill_jobs (status);
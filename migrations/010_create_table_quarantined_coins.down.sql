@@ -0,0 +1 @@
+DROP TABLE IF EXISTS quarantined_coins;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS quarantined_coins (
+    id_quarantined serial PRIMARY KEY,
+	name varchar(256) NOT NULL,
+	quote varchar(16) NOT NULL DEFAULT 'USD',
+	price numeric(22,12) NOT NULL,
+	fixation_time bigint NOT NULL,
+	reason varchar(32) NOT NULL,
+	detail text NOT NULL DEFAULT '',
+	median numeric(22,12),
+	created_at bigint NOT NULL
+);
+
+CREATE INDEX idx_quarantined_coins_name_time ON quarantined_coins (name, quote, fixation_time);
@@ -0,0 +1,3 @@
+ALTER TABLE coins DROP COLUMN IF EXISTS provider_time;
+ALTER TABLE coins DROP COLUMN IF EXISTS provider;
+ALTER TABLE coins DROP COLUMN IF EXISTS ingested_at;
@@ -0,0 +1,3 @@
+ALTER TABLE coins ADD COLUMN ingested_at bigint;
+ALTER TABLE coins ADD COLUMN provider varchar(64);
+ALTER TABLE coins ADD COLUMN provider_time double precision;
@@ -0,0 +1,5 @@
+ALTER TABLE alerts
+    DROP COLUMN IF EXISTS indicator_interval,
+    DROP COLUMN IF EXISTS indicator_period,
+    DROP COLUMN IF EXISTS indicator_value,
+    DROP COLUMN IF EXISTS indicator;
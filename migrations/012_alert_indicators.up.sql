@@ -0,0 +1,5 @@
+ALTER TABLE alerts
+    ADD COLUMN indicator varchar(16),
+    ADD COLUMN indicator_value double precision,
+    ADD COLUMN indicator_period integer,
+    ADD COLUMN indicator_interval varchar(32);
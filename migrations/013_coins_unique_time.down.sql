@@ -0,0 +1,10 @@
+ALTER TABLE coins DROP CONSTRAINT IF EXISTS coins_name_quote_time_key;
+CREATE INDEX IF NOT EXISTS idx_coin_quote_timestamp ON coins (name, quote, fixation_time);
+
+-- Возвращаем повторы, перенесённые в карантин миграцией
+INSERT INTO coins (name, quote, price, fixation_time)
+SELECT name, quote, price, fixation_time
+FROM quarantined_coins
+WHERE reason = 'duplicate' AND detail = 'migration 013';
+
+DELETE FROM quarantined_coins WHERE reason = 'duplicate' AND detail = 'migration 013';
@@ -0,0 +1,13 @@
+-- Повторы не удаляются бесследно: они переносятся в карантин с причиной duplicate и учитываются в отчёте о качестве
+INSERT INTO quarantined_coins (name, quote, price, fixation_time, reason, detail, created_at)
+SELECT a.name, a.quote, a.price, a.fixation_time, 'duplicate', 'migration 013', (extract(epoch FROM now()) * 1000)::bigint
+FROM coins a
+WHERE EXISTS (SELECT 1 FROM coins b
+              WHERE b.name = a.name AND b.quote = a.quote AND b.fixation_time = a.fixation_time AND b.id_coin < a.id_coin);
+
+DELETE FROM coins a
+USING coins b
+WHERE a.name = b.name AND a.quote = b.quote AND a.fixation_time = b.fixation_time AND a.id_coin > b.id_coin;
+
+DROP INDEX IF EXISTS idx_coin_quote_timestamp;
+ALTER TABLE coins ADD CONSTRAINT coins_name_quote_time_key UNIQUE (name, quote, fixation_time);
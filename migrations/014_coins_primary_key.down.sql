@@ -0,0 +1 @@
+ALTER TABLE coins DROP CONSTRAINT IF EXISTS coins_pkey;
@@ -0,0 +1 @@
+ALTER TABLE coins ADD CONSTRAINT coins_pkey PRIMARY KEY (id_coin);
@@ -0,0 +1,4 @@
+ALTER TABLE coins ADD COLUMN source varchar(16) NOT NULL DEFAULT 'live';
+
+-- До появления колонки цены из загрузки истории и дозаполнения пропусков отличались только отсутствием ingested_at
+UPDATE coins SET source = 'backfill' WHERE provider IS NOT NULL AND ingested_at IS NULL;